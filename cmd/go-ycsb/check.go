@@ -0,0 +1,128 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/go-ycsb/pkg/history"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// maxPrintedEvents is the most operations printed for a violation.
+const maxPrintedEvents = 20
+
+var checkModels string
+
+func runCheckCommandFunc(cmd *cobra.Command, args []string) {
+	path := args[0]
+	f, err := os.Open(path)
+	if err != nil {
+		util.Fatalf("open history %s failed %v", path, err)
+	}
+	defer f.Close()
+
+	c := history.NewChecker()
+	r := history.NewReader(f)
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			util.Fatalf("read history %s failed %v", path, err)
+		}
+		c.Add(e)
+	}
+	if !c.UniqueValues() {
+		fmt.Println("Some values are written more than once, so a read of a value written later may be taken as a read of the value before the history")
+	}
+
+	violations := 0
+	for _, model := range strings.Split(checkModels, ",") {
+		res, err := c.Check(strings.TrimSpace(model))
+		if err != nil {
+			util.Fatalf("%v", err)
+		}
+
+		fmt.Printf("***** %s *****\n", res.Model)
+		for _, v := range res.Violations {
+			fmt.Printf("%s/%s: %s\n", v.Table, v.Key, v.Reason)
+			printEvents(v.Events)
+		}
+		if len(res.Unknown) > 0 {
+			fmt.Printf("%d keys are too concurrent to check: %s\n", len(res.Unknown), strings.Join(res.Unknown, ", "))
+		}
+		fmt.Printf("%d operations on %d keys, %d violations\n", res.Events, res.Keys, len(res.Violations))
+		violations += len(res.Violations)
+	}
+
+	if violations > 0 {
+		os.Exit(1)
+	}
+}
+
+func printEvents(events []*history.Event) {
+	header := []string{"Thread", "Operation", "Invoke(ns)", "Complete(ns)", "Values", "Error"}
+	lines := make([][]string, 0, len(events))
+	for i, e := range events {
+		if i == maxPrintedEvents {
+			lines = append(lines, []string{"...", fmt.Sprintf("%d more", len(events)-i), "", "", "", ""})
+			break
+		}
+		lines = append(lines, []string{
+			strconv.Itoa(e.Thread),
+			e.Op,
+			strconv.FormatInt(e.Invoke, 10),
+			strconv.FormatInt(e.Complete, 10),
+			formatEventValues(e),
+			e.Error,
+		})
+	}
+	util.RenderTable(os.Stdout, header, lines)
+}
+
+func formatEventValues(e *history.Event) string {
+	if len(e.Values) == 0 {
+		if e.Op == history.OpRead && e.Error == "" {
+			return "not found"
+		}
+		return ""
+	}
+	values := make([]string, 0, len(e.Values))
+	for field, v := range e.Values {
+		values = append(values, field+"="+v)
+	}
+	sort.Strings(values)
+	return strings.Join(values, " ")
+}
+
+func newCheckCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "check history",
+		Short: "Check the history recorded with history.file against consistency models per key, exit with 1 on violation",
+		Args:  cobra.ExactArgs(1),
+		Run:   runCheckCommandFunc,
+	}
+
+	m.Flags().StringVarP(&checkModels, "models", "m", history.Linearizable,
+		fmt.Sprintf("Comma separated models to check, %s, %s or %s", history.Linearizable, history.ReadYourWrites, history.MonotonicReads))
+	return m
+}
@@ -21,6 +21,9 @@ import (
 	"github.com/pingcap/go-ycsb/pkg/client"
 	"github.com/pingcap/go-ycsb/pkg/measurement"
 	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/report"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
 	"github.com/spf13/cobra"
 )
 
@@ -58,9 +61,31 @@ func runClientCommandFunc(cmd *cobra.Command, args []string, doTransactions bool
 	c := client.NewClient(globalProps, globalWorkload, globalDB)
 	start := time.Now()
 	c.Run(globalContext)
+	end := time.Now()
 
-	fmt.Printf("Run finished, takes %s\n", time.Now().Sub(start))
+	fmt.Printf("Run finished, takes %s\n", end.Sub(start))
+	fmt.Printf("Run stopped by: %s\n", c.StopReason())
+	c.PrintResources()
+
+	// the report is built before the output, which finishes the measurement
+	var r *report.Report
+	if reportFile != "" {
+		r = report.New(globalProps, dbName, c, start, end)
+	}
 	measurement.Output()
+	if r != nil {
+		if err := r.Write(reportFile); err != nil {
+			util.Fatalf("write report %s failed %v", reportFile, err)
+		}
+	}
+
+	// the data is checked by the driver itself, so the reads aren't measured,
+	// recorded, logged or traced like the operations of the run
+	if w, ok := globalWorkload.(ycsb.ValidatedWorkload); ok && doTransactions && globalContext.Err() == nil {
+		if err := w.Validate(globalContext, globalDB.(client.DbWrapper).DB); err != nil {
+			util.Fatalf("validation failed %v", err)
+		}
+	}
 }
 
 func runLoadCommandFunc(cmd *cobra.Command, args []string) {
@@ -75,6 +100,7 @@ var (
 	threadsArg     int
 	targetArg      int
 	reportInterval int
+	reportFile     string
 )
 
 func initClientCommand(m *cobra.Command) {
@@ -86,6 +112,10 @@ func initClientCommand(m *cobra.Command) {
 	m.Flags().IntVar(&reportInterval, "interval", 10, "Interval of outputting measurements in seconds")
 }
 
+func initReportFlag(m *cobra.Command) {
+	m.Flags().StringVar(&reportFile, "report", "", "Write the JSON report of the run with its configuration and results to the file")
+}
+
 func newLoadCommand() *cobra.Command {
 	m := &cobra.Command{
 		Use:   "load db",
@@ -95,6 +125,7 @@ func newLoadCommand() *cobra.Command {
 	}
 
 	initClientCommand(m)
+	initReportFlag(m)
 	return m
 }
 
@@ -107,5 +138,6 @@ func newRunCommand() *cobra.Command {
 	}
 
 	initClientCommand(m)
+	initReportFlag(m)
 	return m
 }
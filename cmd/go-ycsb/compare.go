@@ -0,0 +1,93 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pingcap/go-ycsb/pkg/report"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var compareThresholds report.Thresholds
+
+func runCompareCommandFunc(cmd *cobra.Command, args []string) {
+	results := make([]*report.Result, len(args))
+	for i, path := range args {
+		r, err := report.LoadResult(path)
+		if err != nil {
+			util.Fatalf("load %s failed %v", path, err)
+		}
+		results[i] = r
+	}
+
+	regressions, uncompared := 0, 0
+	for i := 1; i < len(args); i++ {
+		fmt.Printf("***** %s vs %s *****\n", args[i], args[0])
+
+		deltas := report.Compare(results[0], results[i], compareThresholds)
+		if len(deltas) == 0 {
+			fmt.Println("No operation to compare")
+			uncompared++
+			continue
+		}
+
+		header := []string{"Section", "Operation", "Metric", "Base", "Current", "Change(%)", "Result"}
+		lines := make([][]string, 0, len(deltas))
+		for _, d := range deltas {
+			res := "ok"
+			if d.Regression {
+				res = "REGRESSION"
+				regressions++
+			}
+			lines = append(lines, []string{
+				d.Section,
+				d.Operation,
+				d.Metric,
+				util.FloatToOneString(d.Base),
+				util.FloatToOneString(d.Current),
+				fmt.Sprintf("%+.2f", d.Change),
+				res,
+			})
+		}
+		util.RenderTable(os.Stdout, header, lines)
+	}
+
+	if regressions > 0 {
+		fmt.Printf("%d regressions found\n", regressions)
+	}
+	if uncompared > 0 {
+		fmt.Printf("%d runs have no operation to compare\n", uncompared)
+	}
+	if regressions > 0 || uncompared > 0 {
+		os.Exit(1)
+	}
+}
+
+func newCompareCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "compare base current...",
+		Short: "Compare the saved outputs or reports of runs to the base one, exit with 1 on regression or if nothing can be compared",
+		Args:  cobra.MinimumNArgs(2),
+		Run:   runCompareCommandFunc,
+	}
+
+	m.Flags().Float64Var(&compareThresholds.OPS, "ops-threshold", 5, "Maximum drop of OPS in percent, negative disables the check")
+	m.Flags().Float64Var(&compareThresholds.Avg, "avg-threshold", 10, "Maximum increase of the average latency in percent, negative disables the check")
+	m.Flags().Float64Var(&compareThresholds.P99, "p99-threshold", 10, "Maximum increase of the 99th percentile latency in percent, negative disables the check")
+	m.Flags().Float64Var(&compareThresholds.P999, "p999-threshold", 20, "Maximum increase of the 99.9th percentile latency in percent, negative disables the check")
+	return m
+}
@@ -32,8 +32,13 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/pingcap/go-ycsb/pkg/client"
+	"github.com/pingcap/go-ycsb/pkg/history"
 	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/metrics"
+	"github.com/pingcap/go-ycsb/pkg/oplog"
 	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/slowlog"
+	"github.com/pingcap/go-ycsb/pkg/tracing"
 	"github.com/pingcap/go-ycsb/pkg/util"
 	_ "github.com/pingcap/go-ycsb/pkg/workload"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
@@ -112,6 +117,12 @@ func initialGlobal(dbName string, onProperties func()) {
 		onProperties()
 	}
 
+	// the debug server serves the Prometheus metrics too
+	metrics.Init(globalProps, dbName)
+	tracing.Init(globalProps, dbName)
+	slowlog.Init(globalProps)
+	oplog.Init(globalProps)
+	history.Init(globalProps)
 	addr := globalProps.GetString(prop.DebugPprof, prop.DebugPprofDefault)
 	go func() {
 		http.ListenAndServe(addr, nil)
@@ -161,9 +172,11 @@ func main() {
 		case <-sc:
 			// send signal again, return directly
 			fmt.Printf("\nGot signal [%v] again to exit.\n", sig)
+			measurement.Close()
 			os.Exit(1)
 		case <-time.After(10 * time.Second):
 			fmt.Print("\nWait 10s for closed, force exit\n")
+			measurement.Close()
 			os.Exit(1)
 		case <-closeDone:
 			return
@@ -179,6 +192,9 @@ func main() {
 		newShellCommand(),
 		newLoadCommand(),
 		newRunCommand(),
+		newSearchCommand(),
+		newCompareCommand(),
+		newCheckCommand(),
 	)
 
 	cobra.EnablePrefixMatching = true
@@ -191,6 +207,11 @@ func main() {
 	if globalDB != nil {
 		globalDB.Close()
 	}
+	tracing.Close()
+	slowlog.Close()
+	oplog.Close()
+	history.Close()
+	measurement.Close()
 
 	if globalWorkload != nil {
 		globalWorkload.Close()
@@ -0,0 +1,96 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/pingcap/go-ycsb/pkg/client"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+func runSearchCommandFunc(cmd *cobra.Command, args []string) {
+	dbName := args[0]
+
+	initialGlobal(dbName, func() {
+		globalProps.Set(prop.DoTransactions, "true")
+		globalProps.Set(prop.Command, "search")
+
+		if cmd.Flags().Changed("threads") {
+			globalProps.Set(prop.ThreadCount, strconv.Itoa(threadsArg))
+		}
+
+		if cmd.Flags().Changed("target") {
+			globalProps.Set(prop.Target, strconv.Itoa(targetArg))
+		}
+
+		if cmd.Flags().Changed("interval") {
+			globalProps.Set(prop.LogInterval, strconv.Itoa(reportInterval))
+		}
+	})
+
+	fmt.Println("***************** properties *****************")
+	for key, value := range globalProps.Map() {
+		fmt.Printf("\"%s\"=\"%s\"\n", key, value)
+	}
+	fmt.Println("**********************************************")
+
+	s := client.NewSearch(globalProps, globalWorkload, globalDB)
+	best, found := s.Run(globalContext)
+	// every step is a section of the output
+	measurement.Output()
+
+	mode := globalProps.GetString(prop.SearchMode, prop.SearchModeDefault)
+	header := []string{"Step", mode, "OPS", "99th(us)", "99.9th(us)", "ErrorRate(%)", "Result"}
+	lines := make([][]string, 0, len(s.Steps()))
+	for i, step := range s.Steps() {
+		res := "passed"
+		if !step.Passed() {
+			res = step.Violation
+		}
+		lines = append(lines, []string{
+			strconv.Itoa(i + 1),
+			util.IntToString(step.Value),
+			util.FloatToOneString(step.OPS),
+			util.IntToString(step.P99),
+			util.IntToString(step.P999),
+			fmt.Sprintf("%.2f", step.ErrorRate),
+			res,
+		})
+	}
+	util.RenderTable(os.Stdout, header, lines)
+
+	if !found {
+		fmt.Printf("No %s met the SLO\n", mode)
+		return
+	}
+	fmt.Printf("Highest %s meeting the SLO: %d, %s\n", mode, best.Value, best)
+}
+
+func newSearchCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "search db",
+		Short: "YCSB saturation search for the highest target or thread count meeting the SLO",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runSearchCommandFunc,
+	}
+
+	initClientCommand(m)
+	return m
+}
@@ -14,6 +14,7 @@
 package badger
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -148,23 +149,28 @@ func (db *badgerDB) getRowKey(table string, key string) []byte {
 func (db *badgerDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
 	var m map[string][]byte
 	err := db.db.View(func(txn *badger.Txn) error {
-		rowKey := db.getRowKey(table, key)
-		item, err := txn.Get(rowKey)
-		if err != nil {
-			return err
-		}
-		row, err := item.Value()
-		if err != nil {
-			return err
-		}
-
-		m, err = db.r.Decode(row, fields)
+		var err error
+		m, err = db.read(txn, table, key, fields)
 		return err
 	})
 
 	return m, err
 }
 
+func (db *badgerDB) read(txn *badger.Txn, table string, key string, fields []string) (map[string][]byte, error) {
+	rowKey := db.getRowKey(table, key)
+	item, err := txn.Get(rowKey)
+	if err != nil {
+		return nil, err
+	}
+	row, err := item.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	return db.r.Decode(row, fields)
+}
+
 func (db *badgerDB) Scan(ctx context.Context, table string, startKey string, count int, fields []string) ([]map[string][]byte, error) {
 	res := make([]map[string][]byte, count)
 	err := db.db.View(func(txn *badger.Txn) error {
@@ -195,42 +201,92 @@ func (db *badgerDB) Scan(ctx context.Context, table string, startKey string, cou
 	return res, err
 }
 
-func (db *badgerDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
-	err := db.db.Update(func(txn *badger.Txn) error {
-		rowKey := db.getRowKey(table, key)
-		item, err := txn.Get(rowKey)
-		if err != nil {
-			return err
-		}
+func (db *badgerDB) RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, fields []string) ([]map[string][]byte, error) {
+	start, end := util.RowKeyRange(table, startKey, endKey)
+	rowStartKey, rowEndKey := []byte(start), []byte(end)
 
-		value, err := item.Value()
-		if err != nil {
-			return err
-		}
+	var res []map[string][]byte
+	err := db.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = reverse
+		it := txn.NewIterator(opts)
+		defer it.Close()
 
-		data, err := db.r.Decode(value, nil)
-		if err != nil {
-			return err
+		seekKey := rowStartKey
+		if reverse {
+			seekKey = rowEndKey
 		}
+		for it.Seek(seekKey); it.Valid() && len(res) < limit; it.Next() {
+			item := it.Item()
+			key := item.Key()
+			if !reverse && bytes.Compare(key, rowEndKey) >= 0 {
+				break
+			}
+			if reverse {
+				if bytes.Compare(key, rowStartKey) < 0 {
+					break
+				}
+				if bytes.Equal(key, rowEndKey) {
+					// the end is exclusive
+					continue
+				}
+			}
 
-		for field, value := range values {
-			data[field] = value
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+
+			m, err := db.r.Decode(value, fields)
+			if err != nil {
+				return err
+			}
+			res = append(res, m)
 		}
 
-		buf := db.bufPool.Get()
-		defer func() {
-			db.bufPool.Put(buf)
-		}()
+		return nil
+	})
 
-		buf, err = db.r.Encode(buf, data)
-		if err != nil {
-			return err
-		}
-		return txn.Set(rowKey, buf)
+	return res, err
+}
+
+func (db *badgerDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	err := db.db.Update(func(txn *badger.Txn) error {
+		return db.update(txn, table, key, values)
 	})
 	return err
 }
 
+func (db *badgerDB) update(txn *badger.Txn, table string, key string, values map[string][]byte) error {
+	rowKey := db.getRowKey(table, key)
+	item, err := txn.Get(rowKey)
+	if err != nil {
+		return err
+	}
+
+	value, err := item.Value()
+	if err != nil {
+		return err
+	}
+
+	data, err := db.r.Decode(value, nil)
+	if err != nil {
+		return err
+	}
+
+	for field, value := range values {
+		data[field] = value
+	}
+
+	// Txn.Set keeps the value in the pending writes until Commit instead of
+	// copying it, so it's encoded into a new buffer.
+	buf, err := db.r.Encode(nil, data)
+	if err != nil {
+		return err
+	}
+	return txn.Set(rowKey, buf)
+}
+
 func (db *badgerDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
 	err := db.db.Update(func(txn *badger.Txn) error {
 		rowKey := db.getRowKey(table, key)
@@ -258,6 +314,34 @@ func (db *badgerDB) Delete(ctx context.Context, table string, key string) error
 	return err
 }
 
+// Begin starts an optimistic transaction, which fails to commit if any key
+// read in it is changed by another transaction.
+func (db *badgerDB) Begin(ctx context.Context) (ycsb.Txn, error) {
+	return &badgerTxn{db: db, txn: db.db.NewTransaction(true)}, nil
+}
+
+type badgerTxn struct {
+	db  *badgerDB
+	txn *badger.Txn
+}
+
+func (t *badgerTxn) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	return t.db.read(t.txn, table, key, fields)
+}
+
+func (t *badgerTxn) Write(ctx context.Context, table string, key string, values map[string][]byte) error {
+	return t.db.update(t.txn, table, key, values)
+}
+
+func (t *badgerTxn) Commit(ctx context.Context) error {
+	return t.txn.Commit(nil)
+}
+
+func (t *badgerTxn) Abort(ctx context.Context) error {
+	t.txn.Discard()
+	return nil
+}
+
 func init() {
 	ycsb.RegisterDBCreator("badger", badgerCreator{})
 }
@@ -29,6 +29,7 @@
 package boltdb
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -119,23 +120,27 @@ func (db *boltDB) CleanupThread(_ context.Context) {
 func (db *boltDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
 	var m map[string][]byte
 	err := db.db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(table))
-		if bucket == nil {
-			return fmt.Errorf("table not found: %s", table)
-		}
-
-		row := bucket.Get([]byte(key))
-		if row == nil {
-			return fmt.Errorf("key not found: %s.%s", table, key)
-		}
-
 		var err error
-		m, err = db.r.Decode(row, fields)
+		m, err = db.read(tx, table, key, fields)
 		return err
 	})
 	return m, err
 }
 
+func (db *boltDB) read(tx *bolt.Tx, table string, key string, fields []string) (map[string][]byte, error) {
+	bucket := tx.Bucket([]byte(table))
+	if bucket == nil {
+		return nil, fmt.Errorf("table not found: %s", table)
+	}
+
+	row := bucket.Get([]byte(key))
+	if row == nil {
+		return nil, fmt.Errorf("key not found: %s.%s", table, key)
+	}
+
+	return db.r.Decode(row, fields)
+}
+
 func (db *boltDB) Scan(ctx context.Context, table string, startKey string, count int, fields []string) ([]map[string][]byte, error) {
 	res := make([]map[string][]byte, count)
 	err := db.db.View(func(tx *bolt.Tx) error {
@@ -161,42 +166,93 @@ func (db *boltDB) Scan(ctx context.Context, table string, startKey string, count
 	return res, err
 }
 
-func (db *boltDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
-	err := db.db.Update(func(tx *bolt.Tx) error {
+func (db *boltDB) RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, fields []string) ([]map[string][]byte, error) {
+	var res []map[string][]byte
+	err := db.db.View(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(table))
 		if bucket == nil {
 			return fmt.Errorf("table not found: %s", table)
 		}
 
-		value := bucket.Get([]byte(key))
-		if value == nil {
-			return fmt.Errorf("key not found: %s.%s", table, key)
-		}
-
-		data, err := db.r.Decode(value, nil)
-		if err != nil {
-			return err
+		start, end := []byte(startKey), []byte(endKey)
+		cursor := bucket.Cursor()
+		var key, value []byte
+		if !reverse {
+			key, value = cursor.Seek(start)
+		} else if endKey == "" {
+			key, value = cursor.Last()
+		} else {
+			// the last key before the end
+			key, value = cursor.Seek(end)
+			if key == nil {
+				key, value = cursor.Last()
+			} else {
+				key, value = cursor.Prev()
+			}
 		}
 
-		for field, value := range values {
-			data[field] = value
-		}
+		for key != nil && len(res) < limit {
+			if !reverse && endKey != "" && bytes.Compare(key, end) >= 0 {
+				break
+			}
+			if reverse && bytes.Compare(key, start) < 0 {
+				break
+			}
 
-		buf := db.bufPool.Get()
-		defer func() {
-			db.bufPool.Put(buf)
-		}()
+			m, err := db.r.Decode(value, fields)
+			if err != nil {
+				return err
+			}
+			res = append(res, m)
 
-		buf, err = db.r.Encode(buf, data)
-		if err != nil {
-			return err
+			if reverse {
+				key, value = cursor.Prev()
+			} else {
+				key, value = cursor.Next()
+			}
 		}
+		return nil
+	})
+	return res, err
+}
 
-		return bucket.Put([]byte(key), buf)
+func (db *boltDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	err := db.db.Update(func(tx *bolt.Tx) error {
+		return db.update(tx, table, key, values)
 	})
 	return err
 }
 
+func (db *boltDB) update(tx *bolt.Tx, table string, key string, values map[string][]byte) error {
+	bucket := tx.Bucket([]byte(table))
+	if bucket == nil {
+		return fmt.Errorf("table not found: %s", table)
+	}
+
+	value := bucket.Get([]byte(key))
+	if value == nil {
+		return fmt.Errorf("key not found: %s.%s", table, key)
+	}
+
+	data, err := db.r.Decode(value, nil)
+	if err != nil {
+		return err
+	}
+
+	for field, value := range values {
+		data[field] = value
+	}
+
+	// Bucket.Put doesn't copy the value, which must stay valid until the
+	// transaction ends, so it's encoded into a new buffer.
+	buf, err := db.r.Encode(nil, data)
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put([]byte(key), buf)
+}
+
 func (db *boltDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
 	err := db.db.Update(func(tx *bolt.Tx) error {
 		bucket, err := tx.CreateBucketIfNotExists([]byte(table))
@@ -239,6 +295,37 @@ func (db *boltDB) Delete(ctx context.Context, table string, key string) error {
 	return err
 }
 
+// Begin starts a read-write transaction, which excludes the other ones until
+// it ends.
+func (db *boltDB) Begin(ctx context.Context) (ycsb.Txn, error) {
+	tx, err := db.db.Begin(true)
+	if err != nil {
+		return nil, err
+	}
+	return &boltTxn{db: db, tx: tx}, nil
+}
+
+type boltTxn struct {
+	db *boltDB
+	tx *bolt.Tx
+}
+
+func (t *boltTxn) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	return t.db.read(t.tx, table, key, fields)
+}
+
+func (t *boltTxn) Write(ctx context.Context, table string, key string, values map[string][]byte) error {
+	return t.db.update(t.tx, table, key, values)
+}
+
+func (t *boltTxn) Commit(ctx context.Context) error {
+	return t.tx.Commit()
+}
+
+func (t *boltTxn) Abort(ctx context.Context) error {
+	return t.tx.Rollback()
+}
+
 func init() {
 	ycsb.RegisterDBCreator("boltdb", boltCreator{})
 }
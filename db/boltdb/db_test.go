@@ -0,0 +1,53 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boltdb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/dbtest"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+func newTestDB(t *testing.T) ycsb.DB {
+	p := properties.NewProperties()
+	p.Set(boltPath, filepath.Join(t.TempDir(), "bolt.db"))
+	p.Set(prop.FieldCount, "1")
+	db, err := boltCreator{}.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestRangeScan(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	dbtest.RangeScanTest(t, db)
+}
+
+func TestTxn(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	dbtest.TxnTest(t, db)
+}
+
+func TestClosedEconomy(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	dbtest.ClosedEconomyTest(t, db)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/magiconair/properties"
 	"go.etcd.io/etcd/client/pkg/v3/transport"
 
+	"github.com/pingcap/go-ycsb/pkg/util"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 )
 
@@ -136,6 +137,29 @@ func (db *etcdDB) Scan(ctx context.Context, table string, startKey string, count
 	return res, nil
 }
 
+func (db *etcdDB) RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, _ []string) ([]map[string][]byte, error) {
+	rstart, rend := util.RowKeyRange(table, startKey, endKey)
+	opts := []clientv3.OpOption{clientv3.WithRange(rend), clientv3.WithLimit(int64(limit))}
+	if reverse {
+		opts = append(opts, clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend))
+	}
+	values, err := db.client.Get(ctx, rstart, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]map[string][]byte, 0, len(values.Kvs))
+	for _, v := range values.Kvs {
+		var r map[string][]byte
+		err = json.NewDecoder(bytes.NewReader(v.Value)).Decode(&r)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
 func (db *etcdDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
 	rkey := getRowKey(table, key)
 	data, err := json.Marshal(values)
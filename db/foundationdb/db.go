@@ -129,41 +129,78 @@ func (db *fDB) Scan(ctx context.Context, table string, startKey string, count in
 	return res.([]map[string][]byte), nil
 }
 
-func (db *fDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
-	rowKey := db.getRowKey(table, key)
-	_, err := db.db.Transact(func(tr fdb.Transaction) (ret interface{}, e error) {
-		f := tr.Get(fdb.Key(rowKey))
-		row, err := f.Get()
-		if err != nil {
-			return nil, err
-		} else if row == nil {
-			return nil, nil
-		}
-
-		data, err := db.r.Decode(row, nil)
-		if err != nil {
-			return nil, err
+func (db *fDB) RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, fields []string) ([]map[string][]byte, error) {
+	rowEndKey := db.getEndRowKey(table)
+	if endKey != "" {
+		rowEndKey = db.getRowKey(table, endKey)
+	}
+	res, err := db.db.ReadTransact(func(tr fdb.ReadTransaction) (interface{}, error) {
+		r := fdb.KeyRange{
+			Begin: fdb.Key(db.getRowKey(table, startKey)),
+			End:   fdb.Key(rowEndKey),
 		}
+		ri := tr.GetRange(r, fdb.RangeOptions{Limit: limit, Reverse: reverse}).Iterator()
+		res := make([]map[string][]byte, 0, limit)
+		for ri.Advance() {
+			kv, err := ri.Get()
+			if err != nil {
+				return nil, err
+			}
 
-		for field, value := range values {
-			data[field] = value
+			v, err := db.r.Decode(kv.Value, fields)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, v)
 		}
 
-		buf := db.bufPool.Get()
-		defer db.bufPool.Put(buf)
-
-		buf, err = db.r.Encode(buf, data)
-		if err != nil {
-			return nil, err
-		}
+		return res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.([]map[string][]byte), nil
+}
 
-		tr.Set(fdb.Key(rowKey), buf)
-		return
+func (db *fDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	_, err := db.db.Transact(func(tr fdb.Transaction) (interface{}, error) {
+		return nil, db.update(tr, table, key, values)
 	})
 
 	return err
 }
 
+func (db *fDB) update(tr fdb.Transaction, table string, key string, values map[string][]byte) error {
+	rowKey := db.getRowKey(table, key)
+	f := tr.Get(fdb.Key(rowKey))
+	row, err := f.Get()
+	if err != nil {
+		return err
+	} else if row == nil {
+		return nil
+	}
+
+	data, err := db.r.Decode(row, nil)
+	if err != nil {
+		return err
+	}
+
+	for field, value := range values {
+		data[field] = value
+	}
+
+	buf := db.bufPool.Get()
+	defer db.bufPool.Put(buf)
+
+	buf, err = db.r.Encode(buf, data)
+	if err != nil {
+		return err
+	}
+
+	tr.Set(fdb.Key(rowKey), buf)
+	return nil
+}
+
 func (db *fDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
 	// Simulate TiDB data
 	buf := db.bufPool.Get()
@@ -191,6 +228,45 @@ func (db *fDB) Delete(ctx context.Context, table string, key string) error {
 	return err
 }
 
+// Begin starts a transaction, which fails to commit if a key read in it is
+// changed by another transaction after it starts.
+func (db *fDB) Begin(ctx context.Context) (ycsb.Txn, error) {
+	tr, err := db.db.CreateTransaction()
+	if err != nil {
+		return nil, err
+	}
+	return &fdbTxn{db: db, tr: tr}, nil
+}
+
+type fdbTxn struct {
+	db *fDB
+	tr fdb.Transaction
+}
+
+func (t *fdbTxn) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	row, err := t.tr.Get(fdb.Key(t.db.getRowKey(table, key))).Get()
+	if err != nil {
+		return nil, err
+	} else if row == nil {
+		return nil, nil
+	}
+
+	return t.db.r.Decode(row, fields)
+}
+
+func (t *fdbTxn) Write(ctx context.Context, table string, key string, values map[string][]byte) error {
+	return t.db.update(t.tr, table, key, values)
+}
+
+func (t *fdbTxn) Commit(ctx context.Context) error {
+	return t.tr.Commit().Get()
+}
+
+func (t *fdbTxn) Abort(ctx context.Context) error {
+	t.tr.Cancel()
+	return nil
+}
+
 type fdbCreator struct {
 }
 
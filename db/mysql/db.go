@@ -254,6 +254,10 @@ func (db *mysqlDB) queryRows(ctx context.Context, query string, count int, args
 	if err != nil {
 		return nil, err
 	}
+	return db.queryStmtRows(ctx, stmt, count, args...)
+}
+
+func (db *mysqlDB) queryStmtRows(ctx context.Context, stmt *sql.Stmt, count int, args ...interface{}) ([]map[string][]byte, error) {
 	rows, err := stmt.QueryContext(ctx, args...)
 	if err != nil {
 		return nil, err
@@ -352,6 +356,39 @@ func (db *mysqlDB) Scan(ctx context.Context, table string, startKey string, coun
 	return rows, err
 }
 
+func (db *mysqlDB) RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, fields []string) ([]map[string][]byte, error) {
+	columns := "*"
+	if len(fields) > 0 {
+		columns = strings.Join(fields, ",")
+	}
+
+	var conds []string
+	args := make([]interface{}, 0, 3)
+	if startKey != "" {
+		conds = append(conds, "YCSB_KEY >= ?")
+		args = append(args, startKey)
+	}
+	if endKey != "" {
+		conds = append(conds, "YCSB_KEY < ?")
+		args = append(args, endKey)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	order := "ASC"
+	if reverse {
+		order = "DESC"
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`SELECT %s FROM %s %s %s ORDER BY YCSB_KEY %s LIMIT ?`, columns, table, db.forceIndexKeyword, where, order)
+	rows, err := db.queryRows(ctx, query, limit, args...)
+	db.clearCacheIfFailed(ctx, query, err)
+
+	return rows, err
+}
+
 func (db *mysqlDB) execQuery(ctx context.Context, query string, args ...interface{}) error {
 	if db.verbose {
 		fmt.Printf("%s %v\n", query, args)
@@ -368,6 +405,11 @@ func (db *mysqlDB) execQuery(ctx context.Context, query string, args ...interfac
 }
 
 func (db *mysqlDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	query, args := db.updateQuery(table, key, values)
+	return db.execQuery(ctx, query, args...)
+}
+
+func (db *mysqlDB) updateQuery(table string, key string, values map[string][]byte) (string, []interface{}) {
 	buf := bytes.NewBuffer(db.bufPool.Get())
 	defer func() {
 		db.bufPool.Put(buf.Bytes())
@@ -394,7 +436,7 @@ func (db *mysqlDB) Update(ctx context.Context, table string, key string, values
 
 	args = append(args, key)
 
-	return db.execQuery(ctx, buf.String(), args...)
+	return buf.String(), args
 }
 
 func (db *mysqlDB) BatchUpdate(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
@@ -505,6 +547,71 @@ func (db *mysqlDB) Analyze(ctx context.Context, table string) error {
 	return err
 }
 
+// Begin starts a transaction on the connection of the thread, the records
+// read in it are locked by SELECT ... FOR UPDATE.
+func (db *mysqlDB) Begin(ctx context.Context) (ycsb.Txn, error) {
+	state := ctx.Value(stateKey).(*mysqlState)
+	tx, err := state.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &mysqlTxn{db: db, tx: tx}, nil
+}
+
+type mysqlTxn struct {
+	db *mysqlDB
+	tx *sql.Tx
+}
+
+func (t *mysqlTxn) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	columns := "*"
+	if len(fields) > 0 {
+		columns = strings.Join(fields, ",")
+	}
+	query := fmt.Sprintf(`SELECT %s FROM %s %s WHERE YCSB_KEY = ? FOR UPDATE`, columns, table, t.db.forceIndexKeyword)
+	if t.db.verbose {
+		fmt.Printf("%s %v\n", query, key)
+	}
+
+	stmt, err := t.db.getAndCacheStmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := t.db.queryStmtRows(ctx, t.tx.StmtContext(ctx, stmt), 1, key)
+	t.db.clearCacheIfFailed(ctx, query, err)
+
+	if err != nil {
+		return nil, err
+	} else if len(rows) == 0 {
+		return nil, nil
+	}
+
+	return rows[0], nil
+}
+
+func (t *mysqlTxn) Write(ctx context.Context, table string, key string, values map[string][]byte) error {
+	query, args := t.db.updateQuery(table, key, values)
+	if t.db.verbose {
+		fmt.Printf("%s %v\n", query, args)
+	}
+
+	stmt, err := t.db.getAndCacheStmt(ctx, query)
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.StmtContext(ctx, stmt).ExecContext(ctx, args...)
+	t.db.clearCacheIfFailed(ctx, query, err)
+	return err
+}
+
+func (t *mysqlTxn) Commit(ctx context.Context) error {
+	return t.tx.Commit()
+}
+
+func (t *mysqlTxn) Abort(ctx context.Context) error {
+	return t.tx.Rollback()
+}
+
 func init() {
 	ycsb.RegisterDBCreator("mysql", mysqlCreator{name: "mysql"})
 	ycsb.RegisterDBCreator("tidb", mysqlCreator{name: "tidb"})
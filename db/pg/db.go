@@ -205,6 +205,10 @@ func (db *pgDB) queryRows(ctx context.Context, query string, count int, args ...
 	if err != nil {
 		return nil, err
 	}
+	return db.queryStmtRows(ctx, stmt, count, args...)
+}
+
+func (db *pgDB) queryStmtRows(ctx context.Context, stmt *sql.Stmt, count int, args ...interface{}) ([]map[string][]byte, error) {
 	rows, err := stmt.QueryContext(ctx, args...)
 	if err != nil {
 		return nil, err
@@ -272,6 +276,39 @@ func (db *pgDB) Scan(ctx context.Context, table string, startKey string, count i
 	return rows, err
 }
 
+func (db *pgDB) RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, fields []string) ([]map[string][]byte, error) {
+	columns := "*"
+	if len(fields) > 0 {
+		columns = strings.Join(fields, ",")
+	}
+
+	var conds []string
+	args := make([]interface{}, 0, 3)
+	if startKey != "" {
+		args = append(args, startKey)
+		conds = append(conds, fmt.Sprintf("YCSB_KEY >= $%d", len(args)))
+	}
+	if endKey != "" {
+		args = append(args, endKey)
+		conds = append(conds, fmt.Sprintf("YCSB_KEY < $%d", len(args)))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	order := "ASC"
+	if reverse {
+		order = "DESC"
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`SELECT %s FROM %s %s ORDER BY YCSB_KEY %s LIMIT $%d`, columns, table, where, order, len(args))
+	rows, err := db.queryRows(ctx, query, limit, args...)
+	db.clearCacheIfFailed(ctx, query, err)
+
+	return rows, err
+}
+
 func (db *pgDB) execQuery(ctx context.Context, query string, args ...interface{}) error {
 	if db.verbose {
 		fmt.Printf("%s %v\n", query, args)
@@ -288,6 +325,11 @@ func (db *pgDB) execQuery(ctx context.Context, query string, args ...interface{}
 }
 
 func (db *pgDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	query, args := db.updateQuery(table, key, values)
+	return db.execQuery(ctx, query, args...)
+}
+
+func (db *pgDB) updateQuery(table string, key string, values map[string][]byte) (string, []interface{}) {
 	buf := bytes.NewBuffer(db.bufPool.Get())
 	defer func() {
 		db.bufPool.Put(buf.Bytes())
@@ -315,7 +357,7 @@ func (db *pgDB) Update(ctx context.Context, table string, key string, values map
 
 	args = append(args, key)
 
-	return db.execQuery(ctx, buf.String(), args...)
+	return buf.String(), args
 }
 
 func (db *pgDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
@@ -353,6 +395,71 @@ func (db *pgDB) Delete(ctx context.Context, table string, key string) error {
 	return db.execQuery(ctx, query, key)
 }
 
+// Begin starts a transaction on the connection of the thread, the records
+// read in it are locked by SELECT ... FOR UPDATE.
+func (db *pgDB) Begin(ctx context.Context) (ycsb.Txn, error) {
+	state := ctx.Value(stateKey).(*pgState)
+	tx, err := state.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &pgTxn{db: db, tx: tx}, nil
+}
+
+type pgTxn struct {
+	db *pgDB
+	tx *sql.Tx
+}
+
+func (t *pgTxn) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	columns := "*"
+	if len(fields) > 0 {
+		columns = strings.Join(fields, ",")
+	}
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE YCSB_KEY = $1 FOR UPDATE`, columns, table)
+	if t.db.verbose {
+		fmt.Printf("%s %v\n", query, key)
+	}
+
+	stmt, err := t.db.getAndCacheStmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := t.db.queryStmtRows(ctx, t.tx.StmtContext(ctx, stmt), 1, key)
+	t.db.clearCacheIfFailed(ctx, query, err)
+
+	if err != nil {
+		return nil, err
+	} else if len(rows) == 0 {
+		return nil, nil
+	}
+
+	return rows[0], nil
+}
+
+func (t *pgTxn) Write(ctx context.Context, table string, key string, values map[string][]byte) error {
+	query, args := t.db.updateQuery(table, key, values)
+	if t.db.verbose {
+		fmt.Printf("%s %v\n", query, args)
+	}
+
+	stmt, err := t.db.getAndCacheStmt(ctx, query)
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.StmtContext(ctx, stmt).ExecContext(ctx, args...)
+	t.db.clearCacheIfFailed(ctx, query, err)
+	return err
+}
+
+func (t *pgTxn) Commit(ctx context.Context) error {
+	return t.tx.Commit()
+}
+
+func (t *pgTxn) Abort(ctx context.Context) error {
+	return t.tx.Rollback()
+}
+
 func init() {
 	ycsb.RegisterDBCreator("pg", pgCreator{})
 	ycsb.RegisterDBCreator("postgresql", pgCreator{})
@@ -16,6 +16,7 @@
 package rocksdb
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -218,6 +219,53 @@ func (db *rocksDB) Scan(ctx context.Context, table string, startKey string, coun
 	return res, nil
 }
 
+func (db *rocksDB) RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, fields []string) ([]map[string][]byte, error) {
+	start, end := util.RowKeyRange(table, startKey, endKey)
+	rowStartKey, rowEndKey := []byte(start), []byte(end)
+
+	it := db.db.NewIterator(db.readOpts)
+	defer it.Close()
+
+	if reverse {
+		it.SeekForPrev(rowEndKey)
+		if it.Valid() && bytes.Equal(it.Key().Data(), rowEndKey) {
+			// the end is exclusive
+			it.Prev()
+		}
+	} else {
+		it.Seek(rowStartKey)
+	}
+
+	var res []map[string][]byte
+	for it.Valid() && len(res) < limit {
+		key := it.Key().Data()
+		if !reverse && bytes.Compare(key, rowEndKey) >= 0 {
+			break
+		}
+		if reverse && bytes.Compare(key, rowStartKey) < 0 {
+			break
+		}
+
+		m, err := db.r.Decode(cloneValue(it.Value()), fields)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+
+		if reverse {
+			it.Prev()
+		} else {
+			it.Next()
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 func (db *rocksDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
 	m, err := db.Read(ctx, table, key, nil)
 	if err != nil {
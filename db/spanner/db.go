@@ -241,7 +241,11 @@ func (db *spannerDB) queryRows(ctx context.Context, stmt spanner.Statement, coun
 		fmt.Printf("%s %v\n", stmt.SQL, stmt.Params)
 	}
 
-	iter := db.client.Single().Query(ctx, stmt)
+	return db.iterRows(db.client.Single().Query(ctx, stmt), count)
+}
+
+// iterRows reads the rows of the query, in a transaction or not.
+func (db *spannerDB) iterRows(iter *spanner.RowIterator, count int) ([]map[string][]byte, error) {
 	defer iter.Stop()
 
 	vs := make([]map[string][]byte, 0, count)
@@ -280,7 +284,7 @@ func (db *spannerDB) queryRows(ctx context.Context, stmt spanner.Statement, coun
 	return vs, nil
 }
 
-func (db *spannerDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+func readStatement(table string, key string, fields []string) spanner.Statement {
 	var query string
 	if len(fields) == 0 {
 		query = fmt.Sprintf(`SELECT * FROM %s WHERE YCSB_KEY = @key`, table)
@@ -290,8 +294,11 @@ func (db *spannerDB) Read(ctx context.Context, table string, key string, fields
 
 	stmt := spanner.NewStatement(query)
 	stmt.Params["key"] = key
+	return stmt
+}
 
-	rows, err := db.queryRows(ctx, stmt, 1)
+func (db *spannerDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	rows, err := db.queryRows(ctx, readStatement(table, key, fields), 1)
 
 	if err != nil {
 		return nil, err
@@ -319,6 +326,39 @@ func (db *spannerDB) Scan(ctx context.Context, table string, startKey string, co
 	return rows, err
 }
 
+func (db *spannerDB) RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, fields []string) ([]map[string][]byte, error) {
+	columns := "*"
+	if len(fields) > 0 {
+		columns = strings.Join(fields, ",")
+	}
+
+	var conds []string
+	params := map[string]interface{}{"limit": limit}
+	if startKey != "" {
+		conds = append(conds, "YCSB_KEY >= @start")
+		params["start"] = startKey
+	}
+	if endKey != "" {
+		conds = append(conds, "YCSB_KEY < @end")
+		params["end"] = endKey
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	order := "ASC"
+	if reverse {
+		order = "DESC"
+	}
+
+	stmt := spanner.Statement{
+		SQL:    fmt.Sprintf(`SELECT %s FROM %s %s ORDER BY YCSB_KEY %s LIMIT @limit`, columns, table, where, order),
+		Params: params,
+	}
+
+	return db.queryRows(ctx, stmt, limit)
+}
+
 func createMutations(key string, mutations map[string][]byte) ([]string, []interface{}) {
 	keys := make([]string, 0, 1+len(mutations))
 	values := make([]interface{}, 0, 1+len(mutations))
@@ -347,6 +387,51 @@ func (db *spannerDB) Insert(ctx context.Context, table string, key string, mutat
 	return err
 }
 
+// Begin starts a read-write transaction, which locks the records read in it.
+func (db *spannerDB) Begin(ctx context.Context) (ycsb.Txn, error) {
+	tx, err := spanner.NewReadWriteStmtBasedTransaction(ctx, db.client)
+	if err != nil {
+		return nil, err
+	}
+	return &spannerTxn{db: db, tx: tx}, nil
+}
+
+type spannerTxn struct {
+	db *spannerDB
+	tx *spanner.ReadWriteStmtBasedTransaction
+}
+
+func (t *spannerTxn) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	stmt := readStatement(table, key, fields)
+	if t.db.verbose {
+		fmt.Printf("%s %v\n", stmt.SQL, stmt.Params)
+	}
+
+	rows, err := t.db.iterRows(t.tx.Query(ctx, stmt), 1)
+	if err != nil {
+		return nil, err
+	} else if len(rows) == 0 {
+		return nil, nil
+	}
+
+	return rows[0], nil
+}
+
+func (t *spannerTxn) Write(ctx context.Context, table string, key string, values map[string][]byte) error {
+	keys, vals := createMutations(key, values)
+	return t.tx.BufferWrite([]*spanner.Mutation{spanner.Update(table, keys, vals)})
+}
+
+func (t *spannerTxn) Commit(ctx context.Context) error {
+	_, err := t.tx.Commit(ctx)
+	return err
+}
+
+func (t *spannerTxn) Abort(ctx context.Context) error {
+	t.tx.Rollback(ctx)
+	return nil
+}
+
 func (db *spannerDB) Delete(ctx context.Context, table string, key string) error {
 	m := spanner.Delete(table, spanner.Key{key})
 	_, err := db.client.Apply(ctx, []*spanner.Mutation{m})
@@ -257,6 +257,46 @@ func (db *sqliteDB) Scan(ctx context.Context, table string, startKey string, cou
 	return output, err
 }
 
+func (db *sqliteDB) doRangeScan(ctx context.Context, tx *sql.Tx, table string, startKey string, endKey string, reverse bool, limit int, fields []string) ([]map[string][]byte, error) {
+	columns := "*"
+	if len(fields) > 0 {
+		columns = strings.Join(fields, ",")
+	}
+
+	var conds []string
+	args := make([]interface{}, 0, 3)
+	if startKey != "" {
+		conds = append(conds, "YCSB_KEY >= ?")
+		args = append(args, startKey)
+	}
+	if endKey != "" {
+		conds = append(conds, "YCSB_KEY < ?")
+		args = append(args, endKey)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	order := "ASC"
+	if reverse {
+		order = "DESC"
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`SELECT %s FROM %s %s ORDER BY YCSB_KEY %s LIMIT ?`, columns, table, where, order)
+	return db.doQueryRows(ctx, tx, query, limit, args...)
+}
+
+func (db *sqliteDB) RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, fields []string) ([]map[string][]byte, error) {
+	var output []map[string][]byte
+	err := db.optimisticTx(ctx, func(tx *sql.Tx) error {
+		res, err := db.doRangeScan(ctx, tx, table, startKey, endKey, reverse, limit, fields)
+		output = res
+		return err
+	})
+	return output, err
+}
+
 func (db *sqliteDB) doUpdate(ctx context.Context, tx *sql.Tx, table string, key string, values map[string][]byte) error {
 	buf := bytes.NewBuffer(db.bufPool.Get())
 	defer func() {
@@ -393,6 +433,37 @@ func (db *sqliteDB) BatchDelete(ctx context.Context, table string, keys []string
 	})
 }
 
+// Begin starts a transaction, sqlite serializes the transactions with the
+// database lock.
+func (db *sqliteDB) Begin(ctx context.Context) (ycsb.Txn, error) {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteTxn{db: db, tx: tx}, nil
+}
+
+type sqliteTxn struct {
+	db *sqliteDB
+	tx *sql.Tx
+}
+
+func (t *sqliteTxn) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	return t.db.doRead(ctx, t.tx, table, key, fields)
+}
+
+func (t *sqliteTxn) Write(ctx context.Context, table string, key string, values map[string][]byte) error {
+	return t.db.doUpdate(ctx, t.tx, table, key, values)
+}
+
+func (t *sqliteTxn) Commit(ctx context.Context) error {
+	return t.tx.Commit()
+}
+
+func (t *sqliteTxn) Abort(ctx context.Context) error {
+	return t.tx.Rollback()
+}
+
 func init() {
 	ycsb.RegisterDBCreator("sqlite", sqliteCreator{})
 }
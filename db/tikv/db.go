@@ -17,6 +17,7 @@ import (
 	"fmt"
 
 	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/util"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 	"github.com/tikv/client-go/v2/config"
 )
@@ -30,6 +31,12 @@ const (
 	tikvAPIVersion = "tikv.apiversion"
 )
 
+// rangeRowKeys returns the row keys bounding the range of the table.
+func rangeRowKeys(table string, startKey string, endKey string) ([]byte, []byte) {
+	start, end := util.RowKeyRange(table, startKey, endKey)
+	return []byte(start), []byte(end)
+}
+
 type tikvCreator struct {
 }
 
@@ -124,6 +124,34 @@ func (db *rawDB) Scan(ctx context.Context, table string, startKey string, count
 	return res, nil
 }
 
+func (db *rawDB) RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, fields []string) ([]map[string][]byte, error) {
+	rowStartKey, rowEndKey := rangeRowKeys(table, startKey, endKey)
+	var (
+		rows [][]byte
+		err  error
+	)
+	if reverse {
+		// ReverseScan scans [endKey, startKey) from startKey down
+		_, rows, err = db.db.ReverseScan(ctx, rowEndKey, rowStartKey, limit)
+	} else {
+		_, rows, err = db.db.Scan(ctx, rowStartKey, rowEndKey, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]map[string][]byte, len(rows))
+	for i, row := range rows {
+		v, err := db.r.Decode(row, fields)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = v
+	}
+
+	return res, nil
+}
+
 func (db *rawDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
 	row, err := db.db.Get(ctx, db.getRowKey(table, key))
 	if err != nil {
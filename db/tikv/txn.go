@@ -14,6 +14,7 @@
 package tikv
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"strings"
@@ -37,6 +38,15 @@ type txnConfig struct {
 	onePC       bool
 }
 
+// txnIterator is the iterator of the transaction.
+type txnIterator interface {
+	Valid() bool
+	Key() []byte
+	Value() []byte
+	Next() error
+	Close()
+}
+
 type txnDB struct {
 	db      *txnkv.Client
 	r       *util.RowCodec
@@ -180,15 +190,70 @@ func (db *txnDB) Scan(ctx context.Context, table string, startKey string, count
 	return res, nil
 }
 
-func (db *txnDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
-	rowKey := db.getRowKey(table, key)
+func (db *txnDB) RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, fields []string) ([]map[string][]byte, error) {
+	rowStartKey, rowEndKey := rangeRowKeys(table, startKey, endKey)
+
+	tx, err := db.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var it txnIterator
+	if reverse {
+		// the reverse iterator starts from the last key before rowEndKey
+		it, err = tx.IterReverse(rowEndKey)
+	} else {
+		it, err = tx.Iter(rowStartKey, rowEndKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	rows := make([][]byte, 0, limit)
+	for len(rows) < limit && it.Valid() {
+		if reverse && bytes.Compare(it.Key(), rowStartKey) < 0 {
+			break
+		}
+		rows = append(rows, append([]byte{}, it.Value()...))
+		if err = it.Next(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	res := make([]map[string][]byte, len(rows))
+	for i, row := range rows {
+		v, err := db.r.Decode(row, fields)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = v
+	}
+
+	return res, nil
+}
 
+func (db *txnDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
 	tx, err := db.beginTxn()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	if err := db.update(ctx, tx, table, key, values); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (db *txnDB) update(ctx context.Context, tx *transaction.KVTxn, table string, key string, values map[string][]byte) error {
+	rowKey := db.getRowKey(table, key)
 	row, err := tx.Get(ctx, rowKey)
 	if tikverr.IsErrNotFound(err) {
 		return nil
@@ -205,6 +270,8 @@ func (db *txnDB) Update(ctx context.Context, table string, key string, values ma
 		data[field] = value
 	}
 
+	// Set copies the value into the membuffer of the transaction, so the
+	// buffer can go back to the pool.
 	buf := db.bufPool.Get()
 	defer func() {
 		db.bufPool.Put(buf)
@@ -215,11 +282,7 @@ func (db *txnDB) Update(ctx context.Context, table string, key string, values ma
 		return err
 	}
 
-	if err := tx.Set(rowKey, buf); err != nil {
-		return err
-	}
-
-	return tx.Commit(ctx)
+	return tx.Set(rowKey, buf)
 }
 
 func (db *txnDB) BatchUpdate(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
@@ -321,3 +384,41 @@ func (db *txnDB) BatchDelete(ctx context.Context, table string, keys []string) e
 	}
 	return tx.Commit(ctx)
 }
+
+// Begin starts an optimistic transaction, which fails to commit if a key
+// written in it is changed by another transaction after it starts.
+func (db *txnDB) Begin(ctx context.Context) (ycsb.Txn, error) {
+	tx, err := db.beginTxn()
+	if err != nil {
+		return nil, err
+	}
+	return &tikvTxn{db: db, tx: tx}, nil
+}
+
+type tikvTxn struct {
+	db *txnDB
+	tx *transaction.KVTxn
+}
+
+func (t *tikvTxn) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	row, err := t.tx.Get(ctx, t.db.getRowKey(table, key))
+	if tikverr.IsErrNotFound(err) {
+		return nil, nil
+	} else if row == nil {
+		return nil, err
+	}
+
+	return t.db.r.Decode(row, fields)
+}
+
+func (t *tikvTxn) Write(ctx context.Context, table string, key string, values map[string][]byte) error {
+	return t.db.update(ctx, t.tx, table, key, values)
+}
+
+func (t *tikvTxn) Commit(ctx context.Context) error {
+	return t.tx.Commit(ctx)
+}
+
+func (t *tikvTxn) Abort(ctx context.Context) error {
+	return t.tx.Rollback()
+}
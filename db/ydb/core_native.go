@@ -54,36 +54,41 @@ func (d *driverNative) queryRows(ctx context.Context, query string, count int, p
 		if err != nil {
 			return err
 		}
-		defer rows.Close()
+		vs, err = scanResult(ctx, rows, vs)
+		return err
+	}, table.WithIdempotent())
 
-		for rows.NextResultSet(ctx) {
-			resultSet := rows.CurrentResultSet()
+	return vs, err
+}
 
-			for rows.NextRow() {
-				m := make(map[string][]byte, resultSet.ColumnCount())
-				b := make([][]byte, resultSet.ColumnCount())
+func scanResult(ctx context.Context, rows result.BaseResult, vs []map[string][]byte) ([]map[string][]byte, error) {
+	defer rows.Close()
 
-				values := make([]named.Value, 0, resultSet.ColumnCount())
-				resultSet.Columns(func(column options.Column) {
-					values = append(values, named.OptionalWithDefault(column.Name, &b[len(values)]))
-				})
+	for rows.NextResultSet(ctx) {
+		resultSet := rows.CurrentResultSet()
 
-				if err = rows.ScanNamed(values...); err != nil {
-					return err
-				}
+		for rows.NextRow() {
+			m := make(map[string][]byte, resultSet.ColumnCount())
+			b := make([][]byte, resultSet.ColumnCount())
 
-				for i, v := range values {
-					m[v.Name] = b[i]
-				}
+			values := make([]named.Value, 0, resultSet.ColumnCount())
+			resultSet.Columns(func(column options.Column) {
+				values = append(values, named.OptionalWithDefault(column.Name, &b[len(values)]))
+			})
 
-				vs = append(vs, m)
+			if err := rows.ScanNamed(values...); err != nil {
+				return vs, err
 			}
-		}
 
-		return rows.Err()
-	}, table.WithIdempotent())
+			for i, v := range values {
+				m[v.Name] = b[i]
+			}
 
-	return vs, err
+			vs = append(vs, m)
+		}
+	}
+
+	return vs, rows.Err()
 }
 
 func (d *driverNative) executeDataQuery(ctx context.Context, query string, params *table.QueryParameters) error {
@@ -94,6 +99,52 @@ func (d *driverNative) executeDataQuery(ctx context.Context, query string, param
 	}, table.WithIdempotent())
 }
 
+func (d *driverNative) begin(ctx context.Context) (coreTx, error) {
+	s, err := d.db.Table().CreateSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := s.BeginTransaction(ctx, table.TxSettings(table.WithSerializableReadWrite()))
+	if err != nil {
+		s.Close(ctx)
+		return nil, err
+	}
+	return &nativeTx{s: s, tx: tx}, nil
+}
+
+// nativeTx is an interactive serializable transaction in its own session.
+type nativeTx struct {
+	s  table.ClosableSession
+	tx table.Transaction
+}
+
+func (t *nativeTx) queryRows(ctx context.Context, query string, count int, params *table.QueryParameters) ([]map[string][]byte, error) {
+	rows, err := t.tx.Execute(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	return scanResult(ctx, rows, make([]map[string][]byte, 0, count))
+}
+
+func (t *nativeTx) executeDataQuery(ctx context.Context, query string, params *table.QueryParameters) error {
+	rows, err := t.tx.Execute(ctx, query, params)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+func (t *nativeTx) commit(ctx context.Context) error {
+	defer t.s.Close(ctx)
+	_, err := t.tx.CommitTx(ctx)
+	return err
+}
+
+func (t *nativeTx) rollback(ctx context.Context) error {
+	defer t.s.Close(ctx)
+	return t.tx.Rollback(ctx)
+}
+
 func openYdb(ctx context.Context, dsn string, limit int) (_ ydb.Connection, err error) {
 	return ydb.Open(ctx, dsn,
 		environ.WithEnvironCredentials(ctx),
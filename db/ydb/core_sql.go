@@ -36,35 +36,40 @@ func (d *driverSql) queryRows(ctx context.Context, query string, count int, para
 		if err != nil {
 			return err
 		}
-		defer rows.Close()
+		vs, err = scanSqlRows(rows, vs)
+		return err
+	}, retry.WithDoRetryOptions(retry.WithIdempotent(true)))
 
-		cols, err := rows.Columns()
-		if err != nil {
-			return err
+	return vs, err
+}
+
+func scanSqlRows(rows *sql.Rows, vs []map[string][]byte) ([]map[string][]byte, error) {
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return vs, err
+	}
+
+	for rows.Next() {
+		m := make(map[string][]byte, len(cols))
+		dest := make([]interface{}, len(cols))
+		for i := 0; i < len(cols); i++ {
+			v := new([]byte)
+			dest[i] = v
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return vs, err
 		}
 
-		for rows.Next() {
-			m := make(map[string][]byte, len(cols))
-			dest := make([]interface{}, len(cols))
-			for i := 0; i < len(cols); i++ {
-				v := new([]byte)
-				dest[i] = v
-			}
-			if err = rows.Scan(dest...); err != nil {
-				return err
-			}
-
-			for i, v := range dest {
-				m[cols[i]] = *v.(*[]byte)
-			}
-
-			vs = append(vs, m)
+		for i, v := range dest {
+			m[cols[i]] = *v.(*[]byte)
 		}
 
-		return rows.Err()
-	}, retry.WithDoRetryOptions(retry.WithIdempotent(true)))
+		vs = append(vs, m)
+	}
 
-	return vs, err
+	return vs, rows.Err()
 }
 
 func (d *driverSql) executeDataQuery(ctx context.Context, query string, params *table.QueryParameters) error {
@@ -83,6 +88,40 @@ func (d *driverSql) executeSchemeQuery(ctx context.Context, query string) error
 	)
 }
 
+func (d *driverSql) begin(ctx context.Context) (coreTx, error) {
+	tx, err := d.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return nil, err
+	}
+	return &sqlTx{tx: tx}, nil
+}
+
+// sqlTx is an interactive serializable transaction.
+type sqlTx struct {
+	tx *sql.Tx
+}
+
+func (t *sqlTx) queryRows(ctx context.Context, query string, count int, params *table.QueryParameters) ([]map[string][]byte, error) {
+	rows, err := t.tx.QueryContext(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	return scanSqlRows(rows, make([]map[string][]byte, 0, count))
+}
+
+func (t *sqlTx) executeDataQuery(ctx context.Context, query string, params *table.QueryParameters) error {
+	_, err := t.tx.ExecContext(ctx, query, params)
+	return err
+}
+
+func (t *sqlTx) commit(ctx context.Context) error {
+	return t.tx.Commit()
+}
+
+func (t *sqlTx) rollback(ctx context.Context) error {
+	return t.tx.Rollback()
+}
+
 func openSql(ctx context.Context, dsn string, limit int) (*driverSql, error) {
 	cc, err := openYdb(ctx, dsn, limit)
 	if err != nil {
@@ -27,8 +27,10 @@ import (
 	"github.com/ydb-platform/ydb-go-sdk/v3/sugar"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table/types"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/tracing"
 	"github.com/pingcap/go-ycsb/pkg/util"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 )
@@ -42,8 +44,15 @@ type (
 		executeSchemeQuery(ctx context.Context, query string) error
 		queryRows(ctx context.Context, query string, count int, params *table.QueryParameters) ([]map[string][]byte, error)
 		executeDataQuery(ctx context.Context, query string, params *table.QueryParameters) error
+		begin(ctx context.Context) (coreTx, error)
 		close() error
 	}
+	coreTx interface {
+		queryRows(ctx context.Context, query string, count int, params *table.QueryParameters) ([]map[string][]byte, error)
+		executeDataQuery(ctx context.Context, query string, params *table.QueryParameters) error
+		commit(ctx context.Context) error
+		rollback(ctx context.Context) error
+	}
 	driver struct {
 		p            *properties.Properties
 		cores        []driverCore
@@ -58,6 +67,7 @@ type (
 var (
 	_ ycsb.DB      = (*driver)(nil)
 	_ ycsb.BatchDB = (*driver)(nil)
+	_ ycsb.TxnDB   = (*driver)(nil)
 )
 
 func (d *driver) calculateAvgRowSize() int64 {
@@ -192,6 +202,15 @@ var (
 	txControlReadOnly = table.TxControl(table.BeginTx(table.WithSnapshotReadOnly()), table.CommitTx())
 )
 
+// withTraceContext passes the trace context of the traced operation to YDB
+// in the gRPC metadata, so the server-side spans join the trace.
+func withTraceContext(ctx context.Context) context.Context {
+	if tp := tracing.Traceparent(ctx); tp != "" {
+		return metadata.AppendToOutgoingContext(ctx, "traceparent", tp)
+	}
+	return ctx
+}
+
 func (d *driver) queryRows(ctx context.Context, query string, count int, params *table.QueryParameters) (_ []map[string][]byte, err error) {
 	defer func() {
 		if err != nil {
@@ -205,7 +224,7 @@ func (d *driver) queryRows(ctx context.Context, query string, count int, params
 	if !has {
 		return nil, fmt.Errorf("context not contains threadID identifier")
 	}
-	rows, err := d.cores[threadID%len(d.cores)].queryRows(ctx, query, count, params)
+	rows, err := d.cores[threadID%len(d.cores)].queryRows(withTraceContext(ctx), query, count, params)
 	if ydb.IsTimeoutError(err) {
 		return rows, nil
 	}
@@ -213,7 +232,7 @@ func (d *driver) queryRows(ctx context.Context, query string, count int, params
 
 }
 
-func (d *driver) Read(ctx context.Context, tableName string, id string, fields []string) (map[string][]byte, error) {
+func (d *driver) readQuery(tableName string, id string, fields []string) (string, *table.QueryParameters, error) {
 	var (
 		builder = d.buildersPool.Get()
 		params  = table.NewQueryParameters(
@@ -224,7 +243,7 @@ func (d *driver) Read(ctx context.Context, tableName string, id string, fields [
 
 	declares, err := sugar.GenerateDeclareSection(params)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
 	builder.WriteString(declares)
@@ -244,7 +263,16 @@ func (d *driver) Read(ctx context.Context, tableName string, id string, fields [
 	builder.WriteString(tableName)
 	builder.WriteString("\nWHERE id = $id;")
 
-	rows, err := d.queryRows(ctx, builder.String(), 1, params)
+	return builder.String(), params, nil
+}
+
+func (d *driver) Read(ctx context.Context, tableName string, id string, fields []string) (map[string][]byte, error) {
+	query, params, err := d.readQuery(tableName, id, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.queryRows(ctx, query, 1, params)
 	if err != nil {
 		return nil, err
 	}
@@ -296,6 +324,63 @@ func (d *driver) Scan(ctx context.Context, tableName string, startKey string, co
 	return rows, nil
 }
 
+func (d *driver) RangeScan(ctx context.Context, tableName string, startKey string, endKey string, reverse bool, count int, fields []string) ([]map[string][]byte, error) {
+	var (
+		builder      = d.buildersPool.Get()
+		paramOptions = make([]table.ParameterOption, 0, 3)
+		conds        []string
+	)
+	defer d.buildersPool.Put(builder)
+
+	if startKey != "" {
+		paramOptions = append(paramOptions, table.ValueParam("$start", types.TextValue(startKey)))
+		conds = append(conds, "id >= $start")
+	}
+	if endKey != "" {
+		paramOptions = append(paramOptions, table.ValueParam("$end", types.TextValue(endKey)))
+		conds = append(conds, "id < $end")
+	}
+	paramOptions = append(paramOptions, table.ValueParam("$limit", types.Uint64Value(uint64(count))))
+	params := table.NewQueryParameters(paramOptions...)
+
+	declares, err := sugar.GenerateDeclareSection(params)
+	if err != nil {
+		return nil, err
+	}
+
+	builder.WriteString(declares)
+
+	builder.WriteString("SELECT ")
+	if len(fields) == 0 {
+		builder.WriteByte('*')
+	} else {
+		for i, field := range fields {
+			if i != 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteString(field)
+		}
+	}
+	builder.WriteString("\nFROM ")
+	builder.WriteString(tableName)
+	if len(conds) > 0 {
+		builder.WriteString("\nWHERE ")
+		builder.WriteString(strings.Join(conds, " AND "))
+	}
+	builder.WriteString("\nORDER BY id")
+	if reverse {
+		builder.WriteString(" DESC")
+	}
+	builder.WriteString(" LIMIT $limit;")
+
+	rows, err := d.queryRows(ctx, builder.String(), count, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
 func (d *driver) execQuery(ctx context.Context, query string, params *table.QueryParameters) (err error) {
 	defer func() {
 		if err != nil {
@@ -309,14 +394,14 @@ func (d *driver) execQuery(ctx context.Context, query string, params *table.Quer
 	if !has {
 		return fmt.Errorf("context not contains threadID identifier")
 	}
-	err = d.cores[threadID%len(d.cores)].executeDataQuery(ctx, query, params)
+	err = d.cores[threadID%len(d.cores)].executeDataQuery(withTraceContext(ctx), query, params)
 	if ydb.IsTimeoutError(err) {
 		return nil
 	}
 	return err
 }
 
-func (d *driver) insertOrUpsert(ctx context.Context, op string, tableName string, id string, values map[string][]byte) error {
+func (d *driver) insertOrUpsertQuery(op string, tableName string, id string, values map[string][]byte) (string, *table.QueryParameters, error) {
 	var (
 		paramOptions = make([]table.ParameterOption, 0, 1+len(values))
 		pairs        = util.NewFieldPairs(values)
@@ -340,7 +425,7 @@ func (d *driver) insertOrUpsert(ctx context.Context, op string, tableName string
 
 	declares, err := sugar.GenerateDeclareSection(params)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
 
 	builder.WriteString(declares)
@@ -369,7 +454,15 @@ func (d *driver) insertOrUpsert(ctx context.Context, op string, tableName string
 
 	builder.WriteString(");")
 
-	return d.execQuery(ctx, builder.String(), params)
+	return builder.String(), params, nil
+}
+
+func (d *driver) insertOrUpsert(ctx context.Context, op string, tableName string, id string, values map[string][]byte) error {
+	query, params, err := d.insertOrUpsertQuery(op, tableName, id, values)
+	if err != nil {
+		return err
+	}
+	return d.execQuery(ctx, query, params)
 }
 
 func (d *driver) Update(ctx context.Context, table string, id string, values map[string][]byte) error {
@@ -527,3 +620,56 @@ func (d *driver) BatchDelete(ctx context.Context, tableName string, keys []strin
 
 	return d.execQuery(ctx, builder.String(), params)
 }
+
+func (d *driver) Begin(ctx context.Context) (ycsb.Txn, error) {
+	threadID, has := ctx.Value(ctxThreadIDKey{}).(int)
+	if !has {
+		return nil, fmt.Errorf("context not contains threadID identifier")
+	}
+	tx, err := d.cores[threadID%len(d.cores)].begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ydbTxn{d: d, tx: tx}, nil
+}
+
+// ydbTxn is a serializable read-write transaction, its reads take locks
+// which fail the commit if the records are changed by others.
+type ydbTxn struct {
+	d  *driver
+	tx coreTx
+}
+
+func (t *ydbTxn) Read(ctx context.Context, tableName string, id string, fields []string) (map[string][]byte, error) {
+	query, params, err := t.d.readQuery(tableName, id, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := t.tx.queryRows(withTraceContext(ctx), query, 1, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	return rows[0], nil
+}
+
+func (t *ydbTxn) Write(ctx context.Context, tableName string, id string, values map[string][]byte) error {
+	query, params, err := t.d.insertOrUpsertQuery("UPSERT", tableName, id, values)
+	if err != nil {
+		return err
+	}
+	return t.tx.executeDataQuery(withTraceContext(ctx), query, params)
+}
+
+func (t *ydbTxn) Commit(ctx context.Context) error {
+	return t.tx.commit(ctx)
+}
+
+func (t *ydbTxn) Abort(ctx context.Context) error {
+	return t.tx.rollback(ctx)
+}
@@ -19,14 +19,28 @@ import (
 	"math/rand"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/magiconair/properties"
 	"github.com/pingcap/go-ycsb/pkg/measurement"
 	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 )
 
+type contextKey string
+
+const stateKey = contextKey("client")
+
+// workerState is the per-worker state shared with the DbWrapper through the context.
+type workerState struct {
+	threadID int
+	// intendedStart is the time the current operation was scheduled to start at
+	// in open-loop mode, it is zero otherwise.
+	intendedStart time.Time
+}
+
 type worker struct {
 	p               *properties.Properties
 	workDB          ycsb.DB
@@ -38,10 +52,15 @@ type worker struct {
 	targetOpsPerMs  float64
 	threadID        int
 	targetOpsTickNs int64
+	schedule        *schedule
 	opsDone         int64
+	errsDone        int64
+	openLoop        bool
+	state           *workerState
+	stopper         *stopper
 }
 
-func newWorker(p *properties.Properties, threadID int, threadCount int, workload ycsb.Workload, db ycsb.DB) *worker {
+func newWorker(p *properties.Properties, threadID int, threadCount int, workload ycsb.Workload, db ycsb.DB, stopper *stopper, phases []phase) *worker {
 	w := new(worker)
 	w.p = p
 	w.doTransactions = p.GetBool(prop.DoTransactions, true)
@@ -52,6 +71,8 @@ func newWorker(p *properties.Properties, threadID int, threadCount int, workload
 	w.threadID = threadID
 	w.workload = workload
 	w.workDB = db
+	w.state = &workerState{threadID: threadID}
+	w.stopper = stopper
 
 	var totalOpCount int64
 	if w.doTransactions {
@@ -64,7 +85,10 @@ func newWorker(p *properties.Properties, threadID int, threadCount int, workload
 		}
 	}
 
-	if totalOpCount < int64(threadCount) {
+	// a time-bounded run or a finite workload may leave the operation count unlimited
+	_, finite := workload.(ycsb.FiniteWorkload)
+	unlimited := totalOpCount == 0 && (p.GetInt64(prop.MaxExecutiontime, 0) > 0 || len(phases) > 0 || finite)
+	if totalOpCount < int64(threadCount) && !unlimited {
 		fmt.Printf("totalOpCount(%s/%s/%s): %d should be bigger than threadCount: %d",
 			prop.OperationCount,
 			prop.InsertCount,
@@ -77,6 +101,13 @@ func newWorker(p *properties.Properties, threadID int, threadCount int, workload
 
 	w.opCount = totalOpCount / int64(threadCount)
 
+	if len(phases) > 0 {
+		// the phases take the place of target
+		w.schedule = newSchedule(phases, threadCount)
+		w.openLoop = p.GetBool(prop.OpenLoop, prop.OpenLoopDefault)
+		return w
+	}
+
 	targetPerThreadPerms := float64(-1)
 	if v := p.GetInt64(prop.Target, 0); v > 0 {
 		targetPerThread := float64(v) / float64(threadCount)
@@ -86,18 +117,33 @@ func newWorker(p *properties.Properties, threadID int, threadCount int, workload
 	if targetPerThreadPerms > 0 {
 		w.targetOpsPerMs = targetPerThreadPerms
 		w.targetOpsTickNs = int64(1000000.0 / w.targetOpsPerMs)
+		// open-loop scheduling only makes sense with a target throughput
+		w.openLoop = p.GetBool(prop.OpenLoop, prop.OpenLoopDefault)
 	}
 
 	return w
 }
 
+// nextStart returns when the next operation should start, or false if the
+// phases are all done.
+func (w *worker) nextStart(startTime time.Time) (time.Time, bool) {
+	if w.schedule != nil {
+		offset, ok := w.schedule.offset(w.opsDone)
+		return startTime.Add(offset), ok
+	}
+	return startTime.Add(time.Duration(w.opsDone * w.targetOpsTickNs)), true
+}
+
 func (w *worker) throttle(ctx context.Context, startTime time.Time) {
-	if w.targetOpsPerMs <= 0 {
+	if w.targetOpsPerMs <= 0 && w.schedule == nil {
 		return
 	}
 
-	d := time.Duration(w.opsDone * w.targetOpsTickNs)
-	d = startTime.Add(d).Sub(time.Now())
+	next, ok := w.nextStart(startTime)
+	if !ok {
+		return
+	}
+	d := next.Sub(time.Now())
 	if d < 0 {
 		return
 	}
@@ -114,8 +160,29 @@ func (w *worker) run(ctx context.Context) {
 	}
 
 	startTime := time.Now()
+	warmUpFinished := measurement.IsWarmUpFinished()
 
 	for w.opCount == 0 || w.opsDone < w.opCount {
+		if !warmUpFinished && measurement.IsWarmUpFinished() {
+			// start the schedule after warm-up, otherwise the warm-up time is seen
+			// as a backlog of operations which are all late.
+			warmUpFinished = true
+			startTime = time.Now()
+		}
+
+		if w.schedule != nil && warmUpFinished {
+			if _, ok := w.nextStart(startTime); !ok {
+				return
+			}
+		}
+
+		if w.openLoop && warmUpFinished {
+			// the operation is supposed to start at its tick even if the previous
+			// one took longer, so the latency includes the time spent waiting for
+			// a stalled database instead of omitting it.
+			w.state.intendedStart, _ = w.nextStart(startTime)
+		}
+
 		var err error
 		opsCount := 1
 		if w.doTransactions {
@@ -134,12 +201,19 @@ func (w *worker) run(ctx context.Context) {
 			}
 		}
 
+		if err == ycsb.ErrWorkloadDone {
+			return
+		}
 		if err != nil && !w.p.GetBool(prop.Silence, prop.SilenceDefault) {
 			fmt.Printf("operation err: %v\n", err)
 		}
+		w.stopper.record(opsCount, err)
 
 		if measurement.IsWarmUpFinished() {
 			w.opsDone += int64(opsCount)
+			if err != nil {
+				w.errsDone += int64(opsCount)
+			}
 			w.throttle(ctx, startTime)
 		}
 
@@ -156,6 +230,22 @@ type Client struct {
 	p        *properties.Properties
 	workload ycsb.Workload
 	db       ycsb.DB
+
+	stopReason string
+	stats      RunStats
+	resources  *ResourceStats
+}
+
+// RunStats is the summary of a finished run, operations during warm-up are not counted.
+type RunStats struct {
+	// Ops is the number of operations done, a batch counts as batch.size operations.
+	Ops int64
+	// Errors is the number of failed operations.
+	Errors int64
+	// Duration is the measured time after warm-up.
+	Duration time.Duration
+	// WarmUp is how long warm-up takes, which varies in the adaptive mode.
+	WarmUp time.Duration
 }
 
 // NewClient returns a client with the given workload and DB.
@@ -164,29 +254,78 @@ func NewClient(p *properties.Properties, workload ycsb.Workload, db ycsb.DB) *Cl
 	return &Client{p: p, workload: workload, db: db}
 }
 
-// Run runs the workload to the target DB, and blocks until all workers end.
+// Run runs the workload to the target DB, and blocks until all workers end
+// or one of the stop conditions is met.
 func (c *Client) Run(ctx context.Context) {
 	var wg sync.WaitGroup
 	threadCount := c.p.GetInt(prop.ThreadCount, 1)
 
+	phases, err := parsePhases(c.p.GetString(prop.Phases, ""))
+	if err != nil {
+		util.Fatalf("parse %s failed %v", prop.Phases, err)
+	}
+
+	runCtx, runCancel := context.WithCancel(ctx)
+	defer runCancel()
+	stopper := newStopper(c.p, time.Now())
+	go stopper.run(runCtx, runCancel)
+
+	target := &liveTarget{target: float64(c.p.GetInt64(prop.Target, 0)), phases: phases}
+	setLiveTarget(&liveTarget{})
+	defer setLiveTarget(&liveTarget{})
+
 	wg.Add(threadCount)
-	measureCtx, measureCancel := context.WithCancel(ctx)
+	measureCtx, measureCancel := context.WithCancel(runCtx)
 	measureCh := make(chan struct{}, 1)
+	var measureStart time.Time
+	var warmUp time.Duration
+	var monitor *resourceMonitor
+
+	// load stage no need to warm up
+	var detector *warmUpDetector
+	if c.p.GetBool(prop.DoTransactions, true) && c.p.GetBool(prop.WarmUpAdaptive, prop.WarmUpAdaptiveDefault) {
+		detector = newWarmUpDetector(c.p)
+		setWarmUpDetector(detector)
+		defer setWarmUpDetector(nil)
+	}
 	go func() {
 		defer func() {
 			measureCh <- struct{}{}
 		}()
-		// load stage no need to warm up
-		if c.p.GetBool(prop.DoTransactions, true) {
-			dur := c.p.GetInt64(prop.WarmUpTime, 0)
+		if detector != nil {
+			var ok bool
+			if warmUp, ok = detector.wait(runCtx); !ok {
+				return
+			}
+			setWarmUpDetector(nil)
+		} else if c.p.GetBool(prop.DoTransactions, true) {
+			warmUp = time.Duration(c.p.GetInt64(prop.WarmUpTime, 0)) * time.Second
 			select {
-			case <-ctx.Done():
+			case <-runCtx.Done():
 				return
-			case <-time.After(time.Duration(dur) * time.Second):
+			case <-time.After(warmUp):
 			}
 		}
+		// every phase is measured in its own section, which starts before
+		// warm-up is finished to not miss the first operations.
+		var phaseTimer *time.Timer
+		var phaseCh <-chan time.Time
+		phaseIdx := 0
+		if len(phases) > 0 {
+			measurement.StartSection(phaseName(phaseIdx, phases[phaseIdx]))
+			phaseTimer = time.NewTimer(phases[phaseIdx].dur)
+			defer phaseTimer.Stop()
+			phaseCh = phaseTimer.C
+		}
+
 		// finish warming up
+		measureStart = time.Now()
 		measurement.EnableWarmUp(false)
+		target.start = measureStart
+		setLiveTarget(target)
+		if c.p.GetBool(prop.ClientMonitor, prop.ClientMonitorDefault) {
+			monitor = newResourceMonitor()
+		}
 
 		dur := c.p.GetInt64(prop.LogInterval, 10)
 		t := time.NewTicker(time.Duration(dur) * time.Second)
@@ -196,18 +335,34 @@ func (c *Client) Run(ctx context.Context) {
 			select {
 			case <-t.C:
 				measurement.Summary()
+				if monitor != nil {
+					printResourceStats(monitor.interval())
+				}
+			case <-phaseCh:
+				phaseIdx++
+				if phaseIdx == len(phases) {
+					phaseCh = nil
+					continue
+				}
+				measurement.StartSection(phaseName(phaseIdx, phases[phaseIdx]))
+				phaseTimer.Reset(phases[phaseIdx].dur)
 			case <-measureCtx.Done():
 				return
 			}
 		}
 	}()
 
+	workers := make([]*worker, threadCount)
 	for i := 0; i < threadCount; i++ {
 		go func(threadId int) {
 			defer wg.Done()
+			atomic.AddInt64(&runningThreads, 1)
+			defer atomic.AddInt64(&runningThreads, -1)
 
-			w := newWorker(c.p, threadId, threadCount, c.workload, c.db)
-			ctx := c.workload.InitThread(ctx, threadId, threadCount)
+			w := newWorker(c.p, threadId, threadCount, c.workload, c.db, stopper, phases)
+			workers[threadId] = w
+			ctx := context.WithValue(runCtx, stateKey, w.state)
+			ctx = c.workload.InitThread(ctx, threadId, threadCount)
 			ctx = c.db.InitThread(ctx, threadId, threadCount)
 			w.run(ctx)
 			c.db.CleanupThread(ctx)
@@ -222,6 +377,57 @@ func (c *Client) Run(ctx context.Context) {
 			analyzeDB.Analyze(ctx, c.p.GetString(prop.TableName, prop.TableNameDefault))
 		}
 	}
+	end := time.Now()
 	measureCancel()
 	<-measureCh
+
+	c.stats = RunStats{}
+	for _, w := range workers {
+		c.stats.Ops += w.opsDone
+		c.stats.Errors += w.errsDone
+	}
+	if !measureStart.IsZero() {
+		c.stats.Duration = end.Sub(measureStart)
+		c.stats.WarmUp = warmUp
+	}
+	c.resources = nil
+	if monitor != nil {
+		stats := monitor.total()
+		c.resources = &stats
+	}
+
+	c.stopReason = stopper.stopReason()
+	if c.stopReason == "" {
+		if ctx.Err() != nil {
+			c.stopReason = StopReasonInterrupted
+		} else if len(phases) > 0 {
+			c.stopReason = StopReasonPhasesDone
+		} else {
+			c.stopReason = StopReasonFinished
+		}
+	}
+}
+
+// StopReason returns why the last run ended, e.g. a stop condition was met.
+func (c *Client) StopReason() string {
+	return c.stopReason
+}
+
+// Stats returns the summary of the last run.
+func (c *Client) Stats() RunStats {
+	return c.stats
+}
+
+// Resources returns the resource usage of the client in the last run after
+// warm-up, it's nil if the client isn't monitored.
+func (c *Client) Resources() *ResourceStats {
+	return c.resources
+}
+
+// PrintResources prints the resource usage of the client in the last run,
+// and a warning if the client may be the bottleneck.
+func (c *Client) PrintResources() {
+	if c.resources != nil {
+		printResourceStats(*c.resources)
+	}
 }
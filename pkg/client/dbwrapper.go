@@ -16,9 +16,15 @@ package client
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/pingcap/go-ycsb/pkg/history"
 	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/oplog"
+	"github.com/pingcap/go-ycsb/pkg/slowlog"
+	"github.com/pingcap/go-ycsb/pkg/tracing"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 )
 
@@ -27,14 +33,227 @@ type DbWrapper struct {
 	DB ycsb.DB
 }
 
-func measure(start time.Time, op string, err error) {
-	lan := time.Now().Sub(start)
+// valuesSize returns the size of the values, the field names aren't counted.
+func valuesSize(values map[string][]byte) int64 {
+	var n int64
+	for _, v := range values {
+		n += int64(len(v))
+	}
+	return n
+}
+
+func rowsSize(rows []map[string][]byte) int64 {
+	var n int64
+	for _, values := range rows {
+		n += valuesSize(values)
+	}
+	return n
+}
+
+// writtenSize returns the size of the keys plus the values written.
+func writtenSize(keys []string, values []map[string][]byte) int64 {
+	var n int64
+	for _, key := range keys {
+		n += int64(len(key))
+	}
+	return n + rowsSize(values)
+}
+
+// operation is an operation in flight, it's measured, traced and logged if
+// it's slow when it's done.
+type operation struct {
+	name  string
+	table string
+	key   string
+	// bound is the other bound of a range scan, the end key of a scan or the
+	// start key of a reverse scan.
+	bound string
+	// keys are the keys of the batch operations.
+	keys   []string
+	fields []string
+	// values are the values written, the fields of a batch write are the
+	// fields of its first row.
+	values map[string][]byte
+	// read is the values read by a single read, and rows are the rows read
+	// or written by a batch operation, they are kept for the history.
+	read map[string][]byte
+	rows []map[string][]byte
+	// count is the number of records to scan.
+	count int
+	start time.Time
+	span  *tracing.Span
+}
+
+func startOperation(ctx context.Context, o *operation) context.Context {
+	o.start = time.Now()
+	ctx, o.span = tracing.Start(ctx, o.name, o.table)
+	if o.keys != nil {
+		o.span.SetKeys(o.keys)
+	} else {
+		o.span.SetKey(o.key)
+	}
+	return ctx
+}
+
+// intendedPrefix is the prefix of the operations measured from their
+// intended start time in open-loop mode.
+const intendedPrefix = "INTENDED_"
+
+// finish measures the latency of the operation, and the bytes read and
+// written by it if it succeeds.
+func (o *operation) finish(ctx context.Context, read int64, written int64, err error) {
+	// operations without a worker, like in the shell, share the measurement
+	threadID := -1
+	var intendedStart time.Time
+	if state, ok := ctx.Value(stateKey).(*workerState); ok {
+		threadID = state.threadID
+		// in open-loop mode the first operation of an iteration is measured from
+		// its intended start time too to correct the coordinated omission, the
+		// later ones aren't charged for the time before them.
+		intendedStart, state.intendedStart = state.intendedStart, time.Time{}
+	}
+	now := time.Now()
+	lan := now.Sub(o.start)
+	if oplog.Enabled() {
+		o.record()
+	}
+	if history.Enabled() {
+		o.logHistory(threadID, now, err)
+	}
+	if slowlog.IsSlow(lan) {
+		slowlog.Log(o.slowEntry(threadID, o.start, lan, err))
+	}
 	if err != nil {
-		measurement.Measure(fmt.Sprintf("%s_ERROR", op), start, lan)
+		class := ClassifyError(err)
+		o.span.End(class, err)
+		measurement.MeasureError(threadID, o.name, class, err.Error(), o.start, lan)
+		if !intendedStart.IsZero() {
+			// a stall ending in a timeout is what the correction is for
+			measurement.MeasureThread(threadID, intendedPrefix+o.name+"_ERROR", intendedStart, now.Sub(intendedStart))
+		}
 		return
 	}
+	o.span.End("", nil)
 
-	measurement.Measure(op, start, lan)
+	measurement.MeasureThread(threadID, o.name, o.start, lan)
+	measurement.MeasurePayload(threadID, o.name, read, written)
+	if !intendedStart.IsZero() {
+		measurement.MeasureThread(threadID, intendedPrefix+o.name, intendedStart, now.Sub(intendedStart))
+	}
+}
+
+func (o *operation) slowEntry(threadID int, start time.Time, lan time.Duration, err error) *slowlog.Entry {
+	e := &slowlog.Entry{
+		Start:     start,
+		ThreadID:  threadID,
+		Op:        o.name,
+		Table:     o.table,
+		Keys:      o.keys,
+		Fields:    o.fields,
+		BatchSize: len(o.keys),
+		Latency:   lan,
+		Err:       err,
+	}
+	if o.keys == nil {
+		e.Keys = []string{o.key}
+		e.BatchSize = 1
+	}
+	if o.values != nil {
+		e.Fields = o.writtenFields()
+	}
+	return e
+}
+
+func (o *operation) writtenFields() []string {
+	fields := make([]string, 0, len(o.values))
+	for field := range o.values {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// record records the operation to the operation log, a batch operation is
+// recorded as an operation per key.
+func (o *operation) record() {
+	op := strings.TrimPrefix(o.name, "BATCH_")
+	switch op {
+	case oplog.OpRead, oplog.OpUpdate, oplog.OpInsert, oplog.OpDelete, oplog.OpScan, oplog.OpReverseScan:
+	default:
+		// the operations of the transactions can't be replayed
+		return
+	}
+
+	r := oplog.Record{
+		Op:     op,
+		Table:  o.table,
+		Key:    o.key,
+		Bound:  o.bound,
+		Fields: o.fields,
+		Count:  o.count,
+	}
+	if len(o.values) > 0 {
+		r.Fields = o.writtenFields()
+		r.ValueSize = int(valuesSize(o.values)) / len(o.values)
+	}
+	if o.keys == nil {
+		oplog.Log(o.start, &r)
+		return
+	}
+	for _, key := range o.keys {
+		keyRecord := r
+		keyRecord.Key = key
+		oplog.Log(o.start, &keyRecord)
+	}
+}
+
+// logHistory records the reads and writes to the history, a batch operation
+// is recorded as an operation per key.
+func (o *operation) logHistory(threadID int, complete time.Time, err error) {
+	op := strings.TrimPrefix(o.name, "BATCH_")
+	switch op {
+	case history.OpRead, history.OpUpdate, history.OpInsert, history.OpDelete:
+	default:
+		return
+	}
+
+	e := history.Event{
+		Thread: threadID,
+		Op:     op,
+		Table:  o.table,
+		Key:    o.key,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	if o.keys == nil {
+		if op == history.OpRead {
+			e.Values = history.HashValues(o.read)
+		} else {
+			e.Values = history.HashValues(o.values)
+		}
+		history.Log(o.start, complete, &e)
+		return
+	}
+	if err == nil && op == history.OpRead && len(o.rows) != len(o.keys) {
+		// the rows can't be matched to the keys
+		return
+	}
+	for i, key := range o.keys {
+		keyEvent := e
+		keyEvent.Key = key
+		if i < len(o.rows) {
+			keyEvent.Values = history.HashValues(o.rows[i])
+		}
+		history.Log(o.start, complete, &keyEvent)
+	}
+}
+
+func firstRow(values []map[string][]byte) map[string][]byte {
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
 }
 
 func (db DbWrapper) Close() error {
@@ -49,21 +268,25 @@ func (db DbWrapper) CleanupThread(ctx context.Context) {
 	db.DB.CleanupThread(ctx)
 }
 
-func (db DbWrapper) Read(ctx context.Context, table string, key string, fields []string) (_ map[string][]byte, err error) {
-	start := time.Now()
+func (db DbWrapper) Read(ctx context.Context, table string, key string, fields []string) (values map[string][]byte, err error) {
+	o := &operation{name: "READ", table: table, key: key, fields: fields}
+	ctx = startOperation(ctx, o)
 	defer func() {
-		measure(start, "READ", err)
+		o.read = values
+		o.finish(ctx, valuesSize(values), 0, err)
 	}()
 
 	return db.DB.Read(ctx, table, key, fields)
 }
 
-func (db DbWrapper) BatchRead(ctx context.Context, table string, keys []string, fields []string) (_ []map[string][]byte, err error) {
+func (db DbWrapper) BatchRead(ctx context.Context, table string, keys []string, fields []string) (rows []map[string][]byte, err error) {
 	batchDB, ok := db.DB.(ycsb.BatchDB)
 	if ok {
-		start := time.Now()
+		o := &operation{name: "BATCH_READ", table: table, keys: keys, fields: fields}
+		ctx = startOperation(ctx, o)
 		defer func() {
-			measure(start, "BATCH_READ", err)
+			o.rows = rows
+			o.finish(ctx, rowsSize(rows), 0, err)
 		}()
 		return batchDB.BatchRead(ctx, table, keys, fields)
 	}
@@ -76,19 +299,40 @@ func (db DbWrapper) BatchRead(ctx context.Context, table string, keys []string,
 	return nil, nil
 }
 
-func (db DbWrapper) Scan(ctx context.Context, table string, startKey string, count int, fields []string) (_ []map[string][]byte, err error) {
-	start := time.Now()
+func (db DbWrapper) Scan(ctx context.Context, table string, startKey string, count int, fields []string) (rows []map[string][]byte, err error) {
+	o := &operation{name: "SCAN", table: table, key: startKey, fields: fields, count: count}
+	ctx = startOperation(ctx, o)
 	defer func() {
-		measure(start, "SCAN", err)
+		o.finish(ctx, rowsSize(rows), 0, err)
 	}()
 
 	return db.DB.Scan(ctx, table, startKey, count, fields)
 }
 
+func (db DbWrapper) RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, fields []string) (rows []map[string][]byte, err error) {
+	rangeDB, ok := db.DB.(ycsb.RangeScanDB)
+	if !ok {
+		return nil, fmt.Errorf("the %T doesn't implement the RangeScanDB interface", db.DB)
+	}
+
+	name, key, bound := "SCAN", startKey, endKey
+	if reverse {
+		name, key, bound = "REVERSE_SCAN", endKey, startKey
+	}
+	o := &operation{name: name, table: table, key: key, bound: bound, fields: fields, count: limit}
+	ctx = startOperation(ctx, o)
+	defer func() {
+		o.finish(ctx, rowsSize(rows), 0, err)
+	}()
+
+	return rangeDB.RangeScan(ctx, table, startKey, endKey, reverse, limit, fields)
+}
+
 func (db DbWrapper) Update(ctx context.Context, table string, key string, values map[string][]byte) (err error) {
-	start := time.Now()
+	o := &operation{name: "UPDATE", table: table, key: key, values: values}
+	ctx = startOperation(ctx, o)
 	defer func() {
-		measure(start, "UPDATE", err)
+		o.finish(ctx, 0, int64(len(key))+valuesSize(values), err)
 	}()
 
 	return db.DB.Update(ctx, table, key, values)
@@ -97,9 +341,10 @@ func (db DbWrapper) Update(ctx context.Context, table string, key string, values
 func (db DbWrapper) BatchUpdate(ctx context.Context, table string, keys []string, values []map[string][]byte) (err error) {
 	batchDB, ok := db.DB.(ycsb.BatchDB)
 	if ok {
-		start := time.Now()
+		o := &operation{name: "BATCH_UPDATE", table: table, keys: keys, values: firstRow(values), rows: values}
+		ctx = startOperation(ctx, o)
 		defer func() {
-			measure(start, "BATCH_UPDATE", err)
+			o.finish(ctx, 0, writtenSize(keys, values), err)
 		}()
 		return batchDB.BatchUpdate(ctx, table, keys, values)
 	}
@@ -113,9 +358,10 @@ func (db DbWrapper) BatchUpdate(ctx context.Context, table string, keys []string
 }
 
 func (db DbWrapper) Insert(ctx context.Context, table string, key string, values map[string][]byte) (err error) {
-	start := time.Now()
+	o := &operation{name: "INSERT", table: table, key: key, values: values}
+	ctx = startOperation(ctx, o)
 	defer func() {
-		measure(start, "INSERT", err)
+		o.finish(ctx, 0, int64(len(key))+valuesSize(values), err)
 	}()
 
 	return db.DB.Insert(ctx, table, key, values)
@@ -124,9 +370,10 @@ func (db DbWrapper) Insert(ctx context.Context, table string, key string, values
 func (db DbWrapper) BatchInsert(ctx context.Context, table string, keys []string, values []map[string][]byte) (err error) {
 	batchDB, ok := db.DB.(ycsb.BatchDB)
 	if ok {
-		start := time.Now()
+		o := &operation{name: "BATCH_INSERT", table: table, keys: keys, values: firstRow(values), rows: values}
+		ctx = startOperation(ctx, o)
 		defer func() {
-			measure(start, "BATCH_INSERT", err)
+			o.finish(ctx, 0, writtenSize(keys, values), err)
 		}()
 		return batchDB.BatchInsert(ctx, table, keys, values)
 	}
@@ -140,9 +387,10 @@ func (db DbWrapper) BatchInsert(ctx context.Context, table string, keys []string
 }
 
 func (db DbWrapper) Delete(ctx context.Context, table string, key string) (err error) {
-	start := time.Now()
+	o := &operation{name: "DELETE", table: table, key: key}
+	ctx = startOperation(ctx, o)
 	defer func() {
-		measure(start, "DELETE", err)
+		o.finish(ctx, 0, int64(len(key)), err)
 	}()
 
 	return db.DB.Delete(ctx, table, key)
@@ -151,9 +399,10 @@ func (db DbWrapper) Delete(ctx context.Context, table string, key string) (err e
 func (db DbWrapper) BatchDelete(ctx context.Context, table string, keys []string) (err error) {
 	batchDB, ok := db.DB.(ycsb.BatchDB)
 	if ok {
-		start := time.Now()
+		o := &operation{name: "BATCH_DELETE", table: table, keys: keys}
+		ctx = startOperation(ctx, o)
 		defer func() {
-			measure(start, "BATCH_DELETE", err)
+			o.finish(ctx, 0, writtenSize(keys, nil), err)
 		}()
 		return batchDB.BatchDelete(ctx, table, keys)
 	}
@@ -172,3 +421,67 @@ func (db DbWrapper) Analyze(ctx context.Context, table string) error {
 	}
 	return nil
 }
+
+func (db DbWrapper) Begin(ctx context.Context) (txn ycsb.Txn, err error) {
+	txnDB, ok := db.DB.(ycsb.TxnDB)
+	if !ok {
+		return nil, fmt.Errorf("the %T doesn't implement the TxnDB interface", db.DB)
+	}
+
+	o := &operation{name: "BEGIN"}
+	ctx = startOperation(ctx, o)
+	defer func() {
+		o.finish(ctx, 0, 0, err)
+	}()
+
+	txn, err = txnDB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return txnWrapper{txn}, nil
+}
+
+// txnWrapper measures the operations of a transaction.
+type txnWrapper struct {
+	txn ycsb.Txn
+}
+
+func (t txnWrapper) Read(ctx context.Context, table string, key string, fields []string) (values map[string][]byte, err error) {
+	o := &operation{name: "TXN_READ", table: table, key: key, fields: fields}
+	ctx = startOperation(ctx, o)
+	defer func() {
+		o.finish(ctx, valuesSize(values), 0, err)
+	}()
+
+	return t.txn.Read(ctx, table, key, fields)
+}
+
+func (t txnWrapper) Write(ctx context.Context, table string, key string, values map[string][]byte) (err error) {
+	o := &operation{name: "TXN_WRITE", table: table, key: key, values: values}
+	ctx = startOperation(ctx, o)
+	defer func() {
+		o.finish(ctx, 0, int64(len(key))+valuesSize(values), err)
+	}()
+
+	return t.txn.Write(ctx, table, key, values)
+}
+
+func (t txnWrapper) Commit(ctx context.Context) (err error) {
+	o := &operation{name: "COMMIT"}
+	ctx = startOperation(ctx, o)
+	defer func() {
+		o.finish(ctx, 0, 0, err)
+	}()
+
+	return t.txn.Commit(ctx)
+}
+
+func (t txnWrapper) Abort(ctx context.Context) (err error) {
+	o := &operation{name: "ABORT"}
+	ctx = startOperation(ctx, o)
+	defer func() {
+		o.finish(ctx, 0, 0, err)
+	}()
+
+	return t.txn.Abort(ctx)
+}
@@ -0,0 +1,130 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/oplog"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// nopDB does nothing for the operations used by the tests.
+type nopDB struct {
+	ycsb.DB
+}
+
+func (nopDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	return nil, nil
+}
+
+func (nopDB) RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, fields []string) ([]map[string][]byte, error) {
+	return nil, nil
+}
+
+func (nopDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	return nil
+}
+
+func (nopDB) Delete(ctx context.Context, table string, key string) error {
+	return context.DeadlineExceeded
+}
+
+type latencyObserver map[string]time.Duration
+
+func (o latencyObserver) Observe(op string, _ time.Time, lan time.Duration) {
+	o[op] = lan
+}
+
+func TestOpenLoopLatency(t *testing.T) {
+	measurement.InitMeasure(properties.NewProperties())
+	lans := make(latencyObserver)
+	measurement.AddObserver(lans)
+
+	// a read-modify-write scheduled a second ago
+	state := &workerState{threadID: 0, intendedStart: time.Now().Add(-time.Second)}
+	ctx := context.WithValue(context.Background(), stateKey, state)
+	db := DbWrapper{DB: nopDB{}}
+	db.Read(ctx, "usertable", "user1", nil)
+	db.Update(ctx, "usertable", "user1", nil)
+
+	if lan := lans["INTENDED_READ"]; lan < time.Second {
+		t.Fatalf("want the read measured from its intended start, but got %s", lan)
+	}
+	for _, op := range []string{"READ", "UPDATE"} {
+		if lan, ok := lans[op]; !ok || lan >= time.Second {
+			t.Fatalf("want %s measured from its actual start, but got %s", op, lan)
+		}
+	}
+	if _, ok := lans["INTENDED_UPDATE"]; ok {
+		t.Fatal("the later operation of the iteration is measured from the intended start")
+	}
+	if !state.intendedStart.IsZero() {
+		t.Fatal("the intended start isn't cleared")
+	}
+
+	// a failed operation is measured from its intended start too
+	state.intendedStart = time.Now().Add(-time.Second)
+	db.Delete(ctx, "usertable", "user1")
+	if lan := lans["INTENDED_DELETE_ERROR"]; lan < time.Second {
+		t.Fatalf("want the failed delete measured from its intended start, but got %s", lan)
+	}
+	if lan, ok := lans["DELETE_ERROR"]; !ok || lan >= time.Second {
+		t.Fatalf("want the failed delete measured from its actual start, but got %s", lan)
+	}
+}
+
+func TestRecordRangeScan(t *testing.T) {
+	measurement.InitMeasure(properties.NewProperties())
+	path := filepath.Join(t.TempDir(), "ops.jsonl")
+	p := properties.NewProperties()
+	p.Set(prop.RecordFile, path)
+	oplog.Init(p)
+
+	db := DbWrapper{DB: nopDB{}}
+	db.RangeScan(context.Background(), "usertable", "user1", "user5", false, 10, nil)
+	db.RangeScan(context.Background(), "usertable", "user1", "user5", true, 10, nil)
+	db.RangeScan(context.Background(), "usertable", "", "", true, 10, nil)
+	oplog.Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r := oplog.NewReader(f)
+	want := []oplog.Record{
+		{Op: oplog.OpScan, Table: "usertable", Key: "user1", Bound: "user5", Count: 10},
+		{Op: oplog.OpReverseScan, Table: "usertable", Key: "user5", Bound: "user1", Count: 10},
+		{Op: oplog.OpReverseScan, Table: "usertable", Count: 10},
+	}
+	for _, w := range want {
+		rec, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec.Offset = 0
+		if !reflect.DeepEqual(*rec, w) {
+			t.Fatalf("want %+v, but got %+v", w, *rec)
+		}
+	}
+}
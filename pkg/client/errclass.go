@@ -0,0 +1,81 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// Error classes of the failed operations.
+const (
+	ErrorClassNotFound   = "NOT_FOUND"
+	ErrorClassTimeout    = "TIMEOUT"
+	ErrorClassConflict   = "CONFLICT"
+	ErrorClassThrottled  = "THROTTLED"
+	ErrorClassConnection = "CONNECTION"
+	ErrorClassOther      = "OTHER"
+)
+
+// errorPatterns are the lower case substrings of the error messages of the
+// databases for every class, checked in order.
+var errorPatterns = []struct {
+	class    string
+	patterns []string
+}{
+	{ErrorClassTimeout, []string{"timeout", "timed out", "deadline exceeded"}},
+	{ErrorClassThrottled, []string{"throttl", "too many requests", "rate limit", "rate exceeded",
+		"server is busy", "resource exhausted", "resource_exhausted", "overloaded"}},
+	{ErrorClassConflict, []string{"conflict", "abort", "deadlock", "serialization failure",
+		"could not serialize", "retry txn", "write too old", "lock wait"}},
+	{ErrorClassConnection, []string{"connection refused", "connection reset", "broken pipe",
+		"no route to host", "connection closed", "use of closed network connection", "unavailable", "eof"}},
+	{ErrorClassNotFound, []string{"not found", "notfound", "no rows", "not exist", "no such key"}},
+}
+
+// ClassifyError returns the class of the error by its type or message.
+func ClassifyError(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return ErrorClassConnection
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorClassTimeout
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, p := range errorPatterns {
+		for _, s := range p.patterns {
+			if strings.Contains(msg, s) {
+				return p.class
+			}
+		}
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return ErrorClassConnection
+	}
+	return ErrorClassOther
+}
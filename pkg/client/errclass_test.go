@@ -0,0 +1,49 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		err   error
+		class string
+	}{
+		{fmt.Errorf("read: %w", context.DeadlineExceeded), ErrorClassTimeout},
+		{&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, ErrorClassConnection},
+		{&net.OpError{Op: "read", Err: errors.New("something")}, ErrorClassConnection},
+		{io.ErrUnexpectedEOF, ErrorClassConnection},
+		{errors.New("key not found: usertable.user1"), ErrorClassNotFound},
+		{errors.New("sql: no rows in result set"), ErrorClassNotFound},
+		{errors.New("Write conflict, txnStartTS=1"), ErrorClassConflict},
+		{errors.New("Deadlock found when trying to get lock"), ErrorClassConflict},
+		{errors.New("ProvisionedThroughputExceededException: rate exceeded"), ErrorClassThrottled},
+		{errors.New("rpc error: code = Unavailable desc = transport is closing"), ErrorClassConnection},
+		{errors.New("i/o timeout"), ErrorClassTimeout},
+		{errors.New("duplicate key"), ErrorClassOther},
+	}
+	for _, c := range cases {
+		if class := ClassifyError(c.err); class != c.class {
+			t.Errorf("%v: want %s, but got %s", c.err, c.class, class)
+		}
+	}
+}
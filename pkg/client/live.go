@@ -0,0 +1,69 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// liveTarget is the target throughput of the running client.
+type liveTarget struct {
+	// start is when the throttling starts, it's zero during warm-up.
+	start  time.Time
+	target float64
+	phases []phase
+}
+
+func (t *liveTarget) rate(now time.Time) float64 {
+	if t.start.IsZero() {
+		return 0
+	}
+	if len(t.phases) == 0 {
+		return t.target
+	}
+
+	elapsed := now.Sub(t.start)
+	for _, p := range t.phases {
+		if elapsed < p.dur {
+			return p.rate(elapsed)
+		}
+		elapsed -= p.dur
+	}
+	return 0
+}
+
+var (
+	runningThreads int64
+	currentTarget  atomic.Value
+)
+
+func setLiveTarget(t *liveTarget) {
+	currentTarget.Store(t)
+}
+
+// RunningThreads returns the number of workers running now.
+func RunningThreads() int64 {
+	return atomic.LoadInt64(&runningThreads)
+}
+
+// CurrentTarget returns the target throughput in ops/s of the running client,
+// it's 0 if the client isn't throttled, like during warm-up.
+func CurrentTarget() float64 {
+	t, ok := currentTarget.Load().(*liveTarget)
+	if !ok {
+		return 0
+	}
+	return t.rate(time.Now())
+}
@@ -0,0 +1,229 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"time"
+)
+
+// The client is reported as a possible bottleneck when it's over any of the
+// thresholds.
+const (
+	cpuBusyThreshold      = 90.0
+	gcPauseThreshold      = time.Millisecond
+	schedLatencyThreshold = time.Millisecond
+)
+
+const (
+	metricGCPauses       = "/gc/pauses:seconds"
+	metricSchedLatencies = "/sched/latencies:seconds"
+	metricHeap           = "/memory/classes/heap/objects:bytes"
+	metricGoroutines     = "/sched/goroutines:goroutines"
+)
+
+// ResourceStats is the resource usage of the client process.
+type ResourceStats struct {
+	// CPU is the CPU time used in percent of GOMAXPROCS cores, it's 0 if the
+	// CPU time isn't available on the platform.
+	CPU        float64
+	GOMAXPROCS int
+	// GCPauseP50, GCPauseP99 and GCPauseMax are the percentiles of the
+	// stop-the-world pauses of GC.
+	GCPauseP50 time.Duration
+	GCPauseP99 time.Duration
+	GCPauseMax time.Duration
+	// SchedLatencyP99 is the 99th percentile of the time goroutines wait to
+	// run after they are ready.
+	SchedLatencyP99 time.Duration
+	// HeapBytes and Goroutines are the current values for an interval, or
+	// the peak values for a run.
+	HeapBytes  uint64
+	Goroutines int64
+}
+
+func (s ResourceStats) String() string {
+	return fmt.Sprintf("CPU(%%): %.1f, GOMAXPROCS: %d, GC Pause 50th(us): %d, GC Pause 99th(us): %d, GC Pause Max(us): %d, Sched Latency 99th(us): %d, Heap(MB): %.1f, Goroutines: %d",
+		s.CPU, s.GOMAXPROCS, s.GCPauseP50.Microseconds(), s.GCPauseP99.Microseconds(), s.GCPauseMax.Microseconds(),
+		s.SchedLatencyP99.Microseconds(), float64(s.HeapBytes)/(1<<20), s.Goroutines)
+}
+
+// Bottlenecks returns why the client itself may limit the throughput, or nil
+// if it looks fine.
+func (s ResourceStats) Bottlenecks() []string {
+	var reasons []string
+	if s.CPU >= cpuBusyThreshold {
+		reasons = append(reasons, fmt.Sprintf("CPU is %.1f%% of %d cores", s.CPU, s.GOMAXPROCS))
+	}
+	if s.GCPauseP99 >= gcPauseThreshold {
+		reasons = append(reasons, fmt.Sprintf("99th GC pause is %s", s.GCPauseP99.Round(time.Microsecond)))
+	}
+	if s.SchedLatencyP99 >= schedLatencyThreshold {
+		reasons = append(reasons, fmt.Sprintf("99th goroutine scheduling latency is %s", s.SchedLatencyP99.Round(time.Microsecond)))
+	}
+	return reasons
+}
+
+// resourceSnapshot is the cumulative resource usage at a time.
+type resourceSnapshot struct {
+	at             time.Time
+	cpu            time.Duration
+	gcPauses       *metrics.Float64Histogram
+	schedLatencies *metrics.Float64Histogram
+	heap           uint64
+	goroutines     int64
+}
+
+func takeResourceSnapshot() resourceSnapshot {
+	samples := []metrics.Sample{
+		{Name: metricGCPauses},
+		{Name: metricSchedLatencies},
+		{Name: metricHeap},
+		{Name: metricGoroutines},
+	}
+	metrics.Read(samples)
+
+	s := resourceSnapshot{at: time.Now()}
+	s.cpu = processCPUTime()
+	// the metrics not supported by the Go version are KindBad and left zero
+	if samples[0].Value.Kind() == metrics.KindFloat64Histogram {
+		s.gcPauses = samples[0].Value.Float64Histogram()
+	}
+	if samples[1].Value.Kind() == metrics.KindFloat64Histogram {
+		s.schedLatencies = samples[1].Value.Float64Histogram()
+	}
+	if samples[2].Value.Kind() == metrics.KindUint64 {
+		s.heap = samples[2].Value.Uint64()
+	}
+	if samples[3].Value.Kind() == metrics.KindUint64 {
+		s.goroutines = int64(samples[3].Value.Uint64())
+	}
+	return s
+}
+
+// histogramDelta returns the counts of the buckets of to since from.
+func histogramDelta(from *metrics.Float64Histogram, to *metrics.Float64Histogram) []uint64 {
+	if to == nil {
+		return nil
+	}
+	counts := append([]uint64(nil), to.Counts...)
+	if from != nil && len(from.Counts) == len(counts) {
+		for i := range counts {
+			counts[i] -= from.Counts[i]
+		}
+	}
+	return counts
+}
+
+// histogramPercentile returns the upper bound of the bucket the percentile
+// falls in, or the lower bound for the last unbounded bucket.
+func histogramPercentile(buckets []float64, counts []uint64, p float64) time.Duration {
+	var total uint64
+	for _, c := range counts {
+		total += c
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(float64(total) * p / 100))
+	if rank == 0 {
+		rank = 1
+	}
+	var n uint64
+	for i, c := range counts {
+		n += c
+		if n < rank {
+			continue
+		}
+		bound := buckets[i+1]
+		if math.IsInf(bound, 1) {
+			bound = buckets[i]
+		}
+		return time.Duration(bound * float64(time.Second))
+	}
+	return 0
+}
+
+func resourceStatsBetween(from resourceSnapshot, to resourceSnapshot) ResourceStats {
+	s := ResourceStats{
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		HeapBytes:  to.heap,
+		Goroutines: to.goroutines,
+	}
+	if wall := to.at.Sub(from.at); wall > 0 && to.cpu > 0 {
+		s.CPU = float64(to.cpu-from.cpu) / float64(wall) / float64(s.GOMAXPROCS) * 100
+	}
+	if to.gcPauses != nil {
+		counts := histogramDelta(from.gcPauses, to.gcPauses)
+		s.GCPauseP50 = histogramPercentile(to.gcPauses.Buckets, counts, 50)
+		s.GCPauseP99 = histogramPercentile(to.gcPauses.Buckets, counts, 99)
+		s.GCPauseMax = histogramPercentile(to.gcPauses.Buckets, counts, 100)
+	}
+	if to.schedLatencies != nil {
+		counts := histogramDelta(from.schedLatencies, to.schedLatencies)
+		s.SchedLatencyP99 = histogramPercentile(to.schedLatencies.Buckets, counts, 99)
+	}
+	return s
+}
+
+// resourceMonitor samples the resource usage of the client with the periodic
+// summaries, so a run limited by the client rather than the database can be
+// told.
+type resourceMonitor struct {
+	start          resourceSnapshot
+	last           resourceSnapshot
+	peakHeap       uint64
+	peakGoroutines int64
+}
+
+func newResourceMonitor() *resourceMonitor {
+	s := takeResourceSnapshot()
+	return &resourceMonitor{start: s, last: s, peakHeap: s.heap, peakGoroutines: s.goroutines}
+}
+
+// interval returns the resource usage since the last interval.
+func (m *resourceMonitor) interval() ResourceStats {
+	s := takeResourceSnapshot()
+	stats := resourceStatsBetween(m.last, s)
+	m.last = s
+	if s.heap > m.peakHeap {
+		m.peakHeap = s.heap
+	}
+	if s.goroutines > m.peakGoroutines {
+		m.peakGoroutines = s.goroutines
+	}
+	return stats
+}
+
+// total returns the resource usage since the monitor starts, with the peak
+// heap size and goroutine count.
+func (m *resourceMonitor) total() ResourceStats {
+	m.interval()
+	stats := resourceStatsBetween(m.start, m.last)
+	stats.HeapBytes = m.peakHeap
+	stats.Goroutines = m.peakGoroutines
+	return stats
+}
+
+func printResourceStats(s ResourceStats) {
+	fmt.Printf("CLIENT - %s\n", s)
+	if reasons := s.Bottlenecks(); len(reasons) > 0 {
+		fmt.Printf("WARNING: the client may be the bottleneck: %s\n", strings.Join(reasons, ", "))
+	}
+}
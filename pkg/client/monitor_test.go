@@ -0,0 +1,45 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"math"
+	"runtime/metrics"
+	"testing"
+	"time"
+)
+
+func TestResourceStatsBetween(t *testing.T) {
+	buckets := []float64{math.Inf(-1), 0.0001, 0.001, 0.01, math.Inf(1)}
+	start := time.Now()
+	from := resourceSnapshot{
+		at:       start,
+		cpu:      time.Second,
+		gcPauses: &metrics.Float64Histogram{Counts: []uint64{10, 0, 0, 0}, Buckets: buckets},
+	}
+	// 98 pauses under 100us, one under 10ms and one longer in the interval
+	to := resourceSnapshot{
+		at:       start.Add(10 * time.Second),
+		cpu:      10 * time.Second,
+		gcPauses: &metrics.Float64Histogram{Counts: []uint64{108, 0, 1, 1}, Buckets: buckets},
+	}
+
+	s := resourceStatsBetween(from, to)
+	if cpu := s.CPU * float64(s.GOMAXPROCS); math.Abs(cpu-90) > 0.01 {
+		t.Fatalf("want 90%% of one core, got %v", cpu)
+	}
+	if s.GCPauseP50 != 100*time.Microsecond || s.GCPauseP99 != 10*time.Millisecond || s.GCPauseMax != 10*time.Millisecond {
+		t.Fatalf("bad GC pauses %s %s %s", s.GCPauseP50, s.GCPauseP99, s.GCPauseMax)
+	}
+}
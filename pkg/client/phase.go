@@ -0,0 +1,144 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// phase is a part of the run whose target throughput changes linearly
+// from the start rate to the end rate, it's constant if both are equal.
+type phase struct {
+	dur time.Duration
+	// ops per second at the beginning and the end of the phase
+	from float64
+	to   float64
+}
+
+func (p phase) String() string {
+	if p.from == p.to {
+		return fmt.Sprintf("%s at %g ops/s", p.dur, p.from)
+	}
+	return fmt.Sprintf("%s ramp from %g to %g ops/s", p.dur, p.from, p.to)
+}
+
+// ops returns the number of operations scheduled in the phase.
+func (p phase) ops() float64 {
+	return (p.from + p.to) / 2 * p.dur.Seconds()
+}
+
+// offset returns when the n-th operation of the phase should start relative
+// to the beginning of the phase, n must be less than ops().
+func (p phase) offset(n float64) time.Duration {
+	d := p.dur.Seconds()
+	var t float64
+	if p.from == p.to {
+		t = n / p.from
+	} else {
+		// solve n = from*t + (to-from)*t^2/(2d) for t
+		a := (p.to - p.from) / (2 * d)
+		t = (-p.from + math.Sqrt(p.from*p.from+4*a*n)) / (2 * a)
+	}
+	return time.Duration(t * float64(time.Second))
+}
+
+// rate returns the target throughput at the time elapsed since the
+// beginning of the phase.
+func (p phase) rate(elapsed time.Duration) float64 {
+	return p.from + (p.to-p.from)*elapsed.Seconds()/p.dur.Seconds()
+}
+
+func phaseName(idx int, p phase) string {
+	return fmt.Sprintf("PHASE %d: %s", idx+1, p)
+}
+
+// parsePhases parses a phase schedule like "60s:1000,300s:1000-20000,600s:20000",
+// every phase is a duration and either a constant target or a linear ramp.
+func parsePhases(s string) ([]phase, error) {
+	var phases []phase
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		seps := strings.SplitN(item, ":", 2)
+		if len(seps) != 2 {
+			return nil, fmt.Errorf("bad phase `%s`, expected format `duration:rate` or `duration:from-to`", item)
+		}
+
+		dur, err := time.ParseDuration(strings.TrimSpace(seps[0]))
+		if err != nil {
+			return nil, fmt.Errorf("bad phase duration `%s`: %v", item, err)
+		}
+		if dur <= 0 {
+			return nil, fmt.Errorf("bad phase `%s`, duration must be positive", item)
+		}
+
+		p := phase{dur: dur}
+		rates := strings.SplitN(seps[1], "-", 2)
+		if p.from, err = strconv.ParseFloat(strings.TrimSpace(rates[0]), 64); err != nil {
+			return nil, fmt.Errorf("bad phase rate `%s`: %v", item, err)
+		}
+		p.to = p.from
+		if len(rates) == 2 {
+			if p.to, err = strconv.ParseFloat(strings.TrimSpace(rates[1]), 64); err != nil {
+				return nil, fmt.Errorf("bad phase rate `%s`: %v", item, err)
+			}
+		}
+		if p.from < 0 || p.to < 0 {
+			return nil, fmt.Errorf("bad phase `%s`, rate must not be negative", item)
+		}
+
+		phases = append(phases, p)
+	}
+	return phases, nil
+}
+
+// schedule tells when each operation of a worker should start following the phases.
+type schedule struct {
+	phases []phase
+}
+
+// newSchedule returns the schedule for one of the threadCount workers,
+// which gets an even share of the target throughput.
+func newSchedule(phases []phase, threadCount int) *schedule {
+	s := &schedule{phases: make([]phase, len(phases))}
+	for i, p := range phases {
+		p.from /= float64(threadCount)
+		p.to /= float64(threadCount)
+		s.phases[i] = p
+	}
+	return s
+}
+
+// offset returns when the n-th operation should start relative to the
+// beginning of the schedule, or false if all the phases are done.
+func (s *schedule) offset(n int64) (time.Duration, bool) {
+	var start time.Duration
+	left := float64(n)
+	for _, p := range s.phases {
+		ops := p.ops()
+		if left < ops {
+			return start + p.offset(left), true
+		}
+		left -= ops
+		start += p.dur
+	}
+	return 0, false
+}
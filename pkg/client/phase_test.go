@@ -0,0 +1,79 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePhases(t *testing.T) {
+	phases, err := parsePhases("60s:1000, 5m:1000-20000,600s:20000,10s:50000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := []phase{
+		{dur: time.Minute, from: 1000, to: 1000},
+		{dur: 5 * time.Minute, from: 1000, to: 20000},
+		{dur: 10 * time.Minute, from: 20000, to: 20000},
+		{dur: 10 * time.Second, from: 50000, to: 50000},
+	}
+	if !reflect.DeepEqual(phases, check) {
+		t.Errorf("want %v, but got %v", check, phases)
+	}
+
+	for _, s := range []string{"60s", "60:1000", "-1s:1000", "60s:a", "60s:1000-", "60s:-1000"} {
+		if _, err := parsePhases(s); err == nil {
+			t.Errorf("expect error for %q", s)
+		}
+	}
+}
+
+func TestScheduleOffset(t *testing.T) {
+	s := newSchedule([]phase{
+		{dur: 10 * time.Second, from: 200, to: 200},
+		{dur: 10 * time.Second, from: 0, to: 400},
+		{dur: 10 * time.Second, from: 400, to: 0},
+	}, 2)
+
+	cases := []struct {
+		n      int64
+		offset time.Duration
+	}{
+		{0, 0},
+		{100, time.Second},
+		{999, 9990 * time.Millisecond},
+		// 1000 ops in the constant phase, the ramp reaches 100 ops/s after 5s
+		{1000, 10 * time.Second},
+		{1250, 15 * time.Second},
+		// 1000 ops in the ramp up, the ramp down halves its rate after 5s
+		{2000, 20 * time.Second},
+		{2750, 25 * time.Second},
+	}
+	for _, c := range cases {
+		offset, ok := s.offset(c.n)
+		if !ok {
+			t.Fatalf("expect op %d to be scheduled", c.n)
+		}
+		if d := offset - c.offset; d < -time.Millisecond || d > time.Millisecond {
+			t.Errorf("op %d: want %s, but got %s", c.n, c.offset, offset)
+		}
+	}
+
+	if _, ok := s.offset(3000); ok {
+		t.Error("expect the schedule to be done")
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// Search modes.
+const (
+	SearchModeTarget  = "target"
+	SearchModeThreads = "threads"
+)
+
+// SLO is the service level objective every step of the search must meet.
+type SLO struct {
+	// P99 and P999 are the maximum latency percentiles of every operation in us,
+	// 0 means not checked.
+	P99  int64
+	P999 int64
+	// ErrorRate is the maximum percentage of failed operations.
+	ErrorRate float64
+	// MinThroughput is the minimum ratio of the throughput achieved to the
+	// target, only checked in the target mode, 0 means not checked.
+	MinThroughput float64
+}
+
+// SearchStep is the result of running the workload with one target or thread count.
+type SearchStep struct {
+	// Value is the target or the thread count of the step.
+	Value     int64
+	OPS       float64
+	P99       int64
+	P999      int64
+	ErrorRate float64
+	// Violation is why the step broke the SLO, empty if it met the SLO.
+	Violation string
+}
+
+// Passed returns whether the step met the SLO.
+func (s SearchStep) Passed() bool {
+	return s.Violation == ""
+}
+
+func (s SearchStep) String() string {
+	res := "passed"
+	if !s.Passed() {
+		res = "failed: " + s.Violation
+	}
+	return fmt.Sprintf("OPS: %.1f, 99th(us): %d, 99.9th(us): %d, ErrorRate: %.2f%%, %s",
+		s.OPS, s.P99, s.P999, s.ErrorRate, res)
+}
+
+// Search finds the highest target throughput or thread count which still
+// meets the SLO. It raises the value by a factor until the SLO is broken,
+// then bisects between the last passed and the first failed value.
+type Search struct {
+	p        *properties.Properties
+	workload ycsb.Workload
+	db       ycsb.DB
+
+	mode      string
+	start     int64
+	factor    float64
+	precision float64
+	maxSteps  int
+	slo       SLO
+	warmUp    bool
+
+	// runStep runs the workload with the value, it's replaced in tests.
+	runStep func(ctx context.Context, v int64) (SearchStep, bool)
+	steps   []SearchStep
+}
+
+// NewSearch returns a search with the given workload and DB, which are
+// reused by every step. The workload and db can't be nil. The properties
+// are copied, so the ones set by the steps don't change p.
+func NewSearch(p *properties.Properties, workload ycsb.Workload, db ycsb.DB) *Search {
+	s := &Search{p: p.FilterPrefix(""), workload: workload, db: db}
+	s.runStep = s.runWorkload
+	s.mode = p.GetString(prop.SearchMode, prop.SearchModeDefault)
+	switch s.mode {
+	case SearchModeTarget:
+		s.start = p.GetInt64(prop.SearchStart, prop.SearchStartTargetDefault)
+	case SearchModeThreads:
+		s.start = p.GetInt64(prop.SearchStart, prop.SearchStartThreadsDefault)
+	default:
+		util.Fatalf("unknown search mode %s", s.mode)
+	}
+	if s.start <= 0 {
+		util.Fatalf("%s must be positive", prop.SearchStart)
+	}
+
+	s.factor = p.GetFloat64(prop.SearchFactor, prop.SearchFactorDefault)
+	if s.factor <= 1 {
+		util.Fatalf("%s must be bigger than 1", prop.SearchFactor)
+	}
+	s.precision = p.GetFloat64(prop.SearchPrecision, prop.SearchPrecisionDefault)
+	s.maxSteps = p.GetInt(prop.SearchMaxSteps, prop.SearchMaxStepsDefault)
+
+	s.slo = SLO{
+		P99:           p.GetInt64(prop.SearchSLOP99, 0),
+		P999:          p.GetInt64(prop.SearchSLOP999, 0),
+		ErrorRate:     p.GetFloat64(prop.SearchSLOErrorRate, prop.SearchSLOErrorRateDefault),
+		MinThroughput: p.GetFloat64(prop.SearchSLOMinThroughput, prop.SearchSLOMinThroughputDefault),
+	}
+	if s.slo.P99 <= 0 && s.slo.P999 <= 0 {
+		util.Fatalf("at least one of %s and %s must be set", prop.SearchSLOP99, prop.SearchSLOP999)
+	}
+
+	s.warmUp = p.GetInt64(prop.WarmUpTime, 0) > 0 || p.GetBool(prop.WarmUpAdaptive, prop.WarmUpAdaptiveDefault)
+
+	// every step runs for a fixed time instead of a number of operations
+	s.p.Set(prop.MaxExecutiontime, strconv.FormatInt(p.GetInt64(prop.SearchStepTime, prop.SearchStepTimeDefault), 10))
+	s.p.Set(prop.OperationCount, "0")
+	return s
+}
+
+// Steps returns the steps done so far.
+func (s *Search) Steps() []SearchStep {
+	return s.steps
+}
+
+// Run runs the search and returns the best step which met the SLO,
+// or false if none did. It stops early when ctx is done.
+func (s *Search) Run(ctx context.Context) (SearchStep, bool) {
+	var best SearchStep
+	found := false
+	lo, hi := int64(0), int64(0)
+
+	// raise the value until the SLO is broken
+	for v := s.start; len(s.steps) < s.maxSteps; v = s.next(v) {
+		step, ok := s.runStep(ctx, v)
+		if !ok {
+			return best, found
+		}
+		s.steps = append(s.steps, step)
+		if !step.Passed() {
+			hi = v
+			break
+		}
+		best, found, lo = step, true, v
+	}
+
+	// then bisect
+	for hi > 0 && len(s.steps) < s.maxSteps {
+		if hi-lo <= 1 || float64(hi-lo) <= float64(lo)*s.precision/100 {
+			break
+		}
+
+		v := lo + (hi-lo)/2
+		step, ok := s.runStep(ctx, v)
+		if !ok {
+			break
+		}
+		s.steps = append(s.steps, step)
+		if step.Passed() {
+			best, found, lo = step, true, v
+		} else {
+			hi = v
+		}
+	}
+	return best, found
+}
+
+func (s *Search) next(v int64) int64 {
+	n := int64(math.Ceil(float64(v) * s.factor))
+	if n == v {
+		n++
+	}
+	return n
+}
+
+// runWorkload runs the workload once with the value, it returns false if
+// the step is interrupted. Every step is a section of the measurement, so
+// the measurement files are shared by the steps.
+func (s *Search) runWorkload(ctx context.Context, v int64) (SearchStep, bool) {
+	switch s.mode {
+	case SearchModeTarget:
+		s.p.Set(prop.Target, strconv.FormatInt(v, 10))
+	case SearchModeThreads:
+		s.p.Set(prop.ThreadCount, strconv.FormatInt(v, 10))
+	}
+
+	n := len(s.steps) + 1
+	fmt.Printf("Search step %d: %s %d\n", n, s.mode, v)
+
+	measurement.StartSection(fmt.Sprintf("STEP %d: %s %d", n, s.mode, v))
+	measurement.SetThreadCount(s.p.GetInt(prop.ThreadCount, 1))
+	measurement.EnableWarmUp(s.warmUp)
+	c := NewClient(s.p, s.workload, s.db)
+	c.Run(ctx)
+	if ctx.Err() != nil {
+		return SearchStep{}, false
+	}
+
+	step := s.evaluate(v, c.Stats(), measurement.Info())
+	fmt.Printf("Search step %d: %s %d, %s\n", n, s.mode, v, step)
+	return step, true
+}
+
+// evaluate checks the result of a step against the SLO.
+func (s *Search) evaluate(v int64, stats RunStats, info map[string]map[string]interface{}) SearchStep {
+	step := SearchStep{Value: v}
+	if stats.Duration > 0 {
+		step.OPS = float64(stats.Ops) / stats.Duration.Seconds()
+	}
+	if stats.Ops > 0 {
+		step.ErrorRate = float64(stats.Errors) * 100 / float64(stats.Ops)
+	}
+
+	// the SLO must be met by every operation
+	for op, opInfo := range info {
+		if strings.HasSuffix(op, "_ERROR") {
+			continue
+		}
+		if p99, ok := opInfo[measurement.PER99TH].(int64); ok && p99 > step.P99 {
+			step.P99 = p99
+		}
+		if p999, ok := opInfo[measurement.PER999TH].(int64); ok && p999 > step.P999 {
+			step.P999 = p999
+		}
+	}
+
+	var violations []string
+	if stats.Ops == 0 {
+		violations = append(violations, "no operation done")
+	}
+	if s.slo.P99 > 0 && step.P99 > s.slo.P99 {
+		violations = append(violations, fmt.Sprintf("99th(us) %d > %d", step.P99, s.slo.P99))
+	}
+	if s.slo.P999 > 0 && step.P999 > s.slo.P999 {
+		violations = append(violations, fmt.Sprintf("99.9th(us) %d > %d", step.P999, s.slo.P999))
+	}
+	if step.ErrorRate > s.slo.ErrorRate {
+		violations = append(violations, fmt.Sprintf("error rate %.2f%% > %.2f%%", step.ErrorRate, s.slo.ErrorRate))
+	}
+	if s.mode == SearchModeTarget && s.slo.MinThroughput > 0 && step.OPS < float64(v)*s.slo.MinThroughput {
+		violations = append(violations, fmt.Sprintf("OPS %.1f < %.0f%% of target", step.OPS, s.slo.MinThroughput*100))
+	}
+	step.Violation = strings.Join(violations, ", ")
+	return step
+}
@@ -0,0 +1,156 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+func newTestSearch(props map[string]string) (*Search, *properties.Properties) {
+	p := properties.NewProperties()
+	p.Set(prop.SearchSLOP99, "1000")
+	for k, v := range props {
+		p.Set(k, v)
+	}
+	return NewSearch(p, nil, nil), p
+}
+
+func TestSearchCopiesProperties(t *testing.T) {
+	s, p := newTestSearch(map[string]string{prop.OperationCount: "100"})
+	s.p.Set(prop.Target, "2000")
+	if p.GetInt64(prop.OperationCount, 0) != 100 || p.GetString(prop.MaxExecutiontime, "") != "" ||
+		p.GetString(prop.Target, "") != "" {
+		t.Fatalf("the search changes the properties %v", p.Map())
+	}
+}
+
+func TestSearchEvaluate(t *testing.T) {
+	s, _ := newTestSearch(map[string]string{prop.SearchSLOP999: "5000"})
+	stats := RunStats{Ops: 10000, Errors: 50, Duration: 10 * time.Second}
+	info := map[string]map[string]interface{}{
+		"READ":          {measurement.PER99TH: int64(800), measurement.PER999TH: int64(1200)},
+		"INTENDED_READ": {measurement.PER99TH: int64(900), measurement.PER999TH: int64(6000)},
+		// the latencies of the failed operations aren't checked
+		"READ_ERROR": {measurement.PER99TH: int64(90000), measurement.PER999TH: int64(90000)},
+	}
+
+	step := s.evaluate(1000, stats, info)
+	if step.OPS != 1000 || step.P99 != 900 || step.P999 != 6000 || step.ErrorRate != 0.5 {
+		t.Fatalf("unexpected step %+v", step)
+	}
+	if step.Violation != "99.9th(us) 6000 > 5000" {
+		t.Fatalf("unexpected violation %q", step.Violation)
+	}
+
+	// the throughput is under 90% of the target
+	step = s.evaluate(2000, stats, map[string]map[string]interface{}{"READ": info["READ"]})
+	if step.Violation != "OPS 1000.0 < 90% of target" {
+		t.Fatalf("unexpected violation %q", step.Violation)
+	}
+
+	step = s.evaluate(1000, RunStats{Ops: 10000, Errors: 200, Duration: 10 * time.Second}, nil)
+	if step.Violation != "error rate 2.00% > 1.00%" {
+		t.Fatalf("unexpected violation %q", step.Violation)
+	}
+
+	if step = s.evaluate(1000, RunStats{}, nil); step.Passed() {
+		t.Fatal("a step without operations passed")
+	}
+}
+
+func TestSearchRun(t *testing.T) {
+	tests := []struct {
+		props map[string]string
+		// the steps pass up to max
+		max   int64
+		steps []int64
+		best  int64
+	}{
+		{
+			props: map[string]string{prop.SearchStart: "10"},
+			max:   37,
+			steps: []int64{10, 20, 40, 30, 35, 37, 38},
+			best:  37,
+		},
+		{
+			// stops bisecting within 20% of the passed value
+			props: map[string]string{prop.SearchStart: "10", prop.SearchPrecision: "20"},
+			max:   37,
+			steps: []int64{10, 20, 40, 30, 35},
+			best:  35,
+		},
+		{
+			props: map[string]string{prop.SearchMode: SearchModeThreads, prop.SearchFactor: "1.5"},
+			max:   4,
+			steps: []int64{1, 2, 3, 5, 4},
+			best:  4,
+		},
+		{
+			props: map[string]string{prop.SearchStart: "10", prop.SearchMaxSteps: "3"},
+			max:   1000,
+			steps: []int64{10, 20, 40},
+			best:  40,
+		},
+		{
+			// bisects under the start if it fails
+			props: map[string]string{prop.SearchStart: "10"},
+			max:   5,
+			steps: []int64{10, 5, 7, 6},
+			best:  5,
+		},
+		{
+			props: map[string]string{prop.SearchStart: "10"},
+			steps: []int64{10, 5, 2, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		s, _ := newTestSearch(tt.props)
+		s.runStep = func(_ context.Context, v int64) (SearchStep, bool) {
+			step := SearchStep{Value: v}
+			if v > tt.max {
+				step.Violation = "too high"
+			}
+			return step, true
+		}
+
+		best, found := s.Run(context.Background())
+		var steps []int64
+		for _, step := range s.Steps() {
+			steps = append(steps, step.Value)
+		}
+		if !reflect.DeepEqual(steps, tt.steps) {
+			t.Errorf("%v: want steps %v, but got %v", tt.props, tt.steps, steps)
+		}
+		if found != (tt.best > 0) || best.Value != tt.best {
+			t.Errorf("%v: want the best %d, but got %d", tt.props, tt.best, best.Value)
+		}
+	}
+
+	// an interrupted step ends the search
+	s, _ := newTestSearch(map[string]string{prop.SearchStart: "10"})
+	s.runStep = func(_ context.Context, v int64) (SearchStep, bool) {
+		return SearchStep{Value: v}, v < 40
+	}
+	if best, found := s.Run(context.Background()); !found || best.Value != 20 || len(s.Steps()) != 2 {
+		t.Fatalf("want the best 20 after 2 steps, but got %d after %d steps", best.Value, len(s.Steps()))
+	}
+}
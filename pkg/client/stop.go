@@ -0,0 +1,189 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// Reasons reported when a run is not ended by a stop condition.
+const (
+	StopReasonFinished    = "all operations done"
+	StopReasonPhasesDone  = "all phases done"
+	StopReasonInterrupted = "interrupted"
+)
+
+const stopCheckInterval = 100 * time.Millisecond
+
+// stopCondition decides whether the run should be stopped early.
+type stopCondition interface {
+	// check returns the reason to stop the run, or an empty string to go on.
+	// ops and errs are the total operations and errors since the run started.
+	check(now time.Time, ops int64, errs int64) string
+}
+
+type maxExecutionTime struct {
+	deadline time.Time
+	dur      time.Duration
+}
+
+func (c *maxExecutionTime) check(now time.Time, _ int64, _ int64) string {
+	if now.Before(c.deadline) {
+		return ""
+	}
+	return fmt.Sprintf("%s %s reached", prop.MaxExecutiontime, c.dur)
+}
+
+type maxErrors struct {
+	limit int64
+}
+
+func (c *maxErrors) check(_ time.Time, _ int64, errs int64) string {
+	if errs < c.limit {
+		return ""
+	}
+	return fmt.Sprintf("%s %d reached", prop.StopMaxErrors, c.limit)
+}
+
+type errorRateSample struct {
+	t    time.Time
+	ops  int64
+	errs int64
+}
+
+// maxErrorRate stops the run when the percentage of failed operations
+// within the sliding window goes above the limit.
+type maxErrorRate struct {
+	limit  float64
+	window time.Duration
+	minOps int64
+
+	samples []errorRateSample
+}
+
+func (c *maxErrorRate) check(now time.Time, ops int64, errs int64) string {
+	c.samples = append(c.samples, errorRateSample{t: now, ops: ops, errs: errs})
+
+	// keep the newest sample which is older than the window as the base
+	i := 0
+	for i+1 < len(c.samples) && now.Sub(c.samples[i+1].t) >= c.window {
+		i++
+	}
+	c.samples = c.samples[i:]
+
+	base := c.samples[0]
+	windowOps := ops - base.ops
+	if windowOps <= 0 || windowOps < c.minOps {
+		return ""
+	}
+
+	rate := float64(errs-base.errs) * 100 / float64(windowOps)
+	if rate <= c.limit {
+		return ""
+	}
+	return fmt.Sprintf("error rate %.2f%% over last %s exceeded %s %.2f%%", rate, c.window, prop.StopErrorRate, c.limit)
+}
+
+// stopper watches the stop conditions and cancels the run when one of them is met.
+type stopper struct {
+	conditions []stopCondition
+	// countOps is false when no condition needs the counters, so the workers
+	// don't have to update them.
+	countOps bool
+
+	ops    int64
+	errs   int64
+	reason atomic.Value
+}
+
+func newStopper(p *properties.Properties, start time.Time) *stopper {
+	s := new(stopper)
+	if v := p.GetInt64(prop.MaxExecutiontime, 0); v > 0 {
+		dur := time.Duration(v) * time.Second
+		s.conditions = append(s.conditions, &maxExecutionTime{deadline: start.Add(dur), dur: dur})
+	}
+
+	if v := p.GetInt64(prop.StopMaxErrors, 0); v > 0 {
+		s.conditions = append(s.conditions, &maxErrors{limit: v})
+		s.countOps = true
+	}
+
+	if v := p.GetFloat64(prop.StopErrorRate, 0); v > 0 {
+		s.conditions = append(s.conditions, &maxErrorRate{
+			limit:  v,
+			window: time.Duration(p.GetInt64(prop.StopErrorRateWindow, prop.StopErrorRateWindowDefault)) * time.Second,
+			minOps: p.GetInt64(prop.StopErrorRateMinOps, prop.StopErrorRateMinOpsDefault),
+		})
+		s.countOps = true
+	}
+	return s
+}
+
+// record counts the finished operations for the stop conditions.
+func (s *stopper) record(ops int, err error) {
+	if !s.countOps {
+		return
+	}
+	atomic.AddInt64(&s.ops, int64(ops))
+	if err != nil {
+		atomic.AddInt64(&s.errs, int64(ops))
+	}
+}
+
+func (s *stopper) check(now time.Time) string {
+	ops := atomic.LoadInt64(&s.ops)
+	errs := atomic.LoadInt64(&s.errs)
+	for _, c := range s.conditions {
+		if reason := c.check(now, ops, errs); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// run checks the stop conditions periodically until ctx is done, and calls
+// cancel with the reason stored once a condition is met.
+func (s *stopper) run(ctx context.Context, cancel context.CancelFunc) {
+	if len(s.conditions) == 0 {
+		return
+	}
+
+	t := time.NewTicker(stopCheckInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			if reason := s.check(now); reason != "" {
+				s.reason.Store(reason)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// stopReason returns the reason of the met stop condition, or an empty string.
+func (s *stopper) stopReason() string {
+	reason, _ := s.reason.Load().(string)
+	return reason
+}
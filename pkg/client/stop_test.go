@@ -0,0 +1,55 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaxErrorRate(t *testing.T) {
+	c := &maxErrorRate{limit: 10, window: 10 * time.Second, minOps: 100}
+	start := time.Now()
+
+	// 5% errors for the first 10s
+	for i := int64(0); i <= 10; i++ {
+		if reason := c.check(start.Add(time.Duration(i)*time.Second), i*100, i*5); reason != "" {
+			t.Fatalf("unexpected stop at %ds: %s", i, reason)
+		}
+	}
+
+	// 50% errors afterwards, the window still holds the old samples,
+	// so the rate only goes above the limit after two seconds
+	if reason := c.check(start.Add(11*time.Second), 1100, 100); reason != "" {
+		t.Fatalf("unexpected stop: %s", reason)
+	}
+	if reason := c.check(start.Add(12*time.Second), 1200, 150); reason == "" {
+		t.Fatal("expect the run to be stopped")
+	}
+}
+
+func TestMaxErrorRateMinOps(t *testing.T) {
+	c := &maxErrorRate{limit: 10, window: 10 * time.Second, minOps: 100}
+	start := time.Now()
+
+	if reason := c.check(start, 0, 0); reason != "" {
+		t.Fatalf("unexpected stop: %s", reason)
+	}
+	if reason := c.check(start.Add(time.Second), 10, 10); reason != "" {
+		t.Fatalf("unexpected stop with too few operations: %s", reason)
+	}
+	if reason := c.check(start.Add(2*time.Second), 100, 20); reason == "" {
+		t.Fatal("expect the run to be stopped")
+	}
+}
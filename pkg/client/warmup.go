@@ -0,0 +1,192 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	hdrhistogram "github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// maxWarmUpLatency is the highest latency in us tracked during warm-up,
+// higher ones are counted as it.
+const maxWarmUpLatency = int64(time.Hour / time.Microsecond)
+
+// warmUpDetector ends warm-up once the throughput and the 99th percentile
+// latency of the last intervals are steady, which is when the coefficient
+// of variation of both over the sliding window is under the threshold.
+type warmUpDetector struct {
+	interval time.Duration
+	window   int
+	maxCV    float64
+	minTime  time.Duration
+	maxTime  time.Duration
+
+	mu    sync.Mutex
+	count int64
+	hist  *hdrhistogram.Histogram
+	// ops and p99s are the throughput and the 99th percentile latency of the
+	// intervals in the window.
+	ops  []float64
+	p99s []float64
+}
+
+func newWarmUpDetector(p *properties.Properties) *warmUpDetector {
+	d := &warmUpDetector{
+		interval: time.Duration(p.GetInt64(prop.WarmUpInterval, prop.WarmUpIntervalDefault)) * time.Second,
+		window:   int(p.GetInt64(prop.WarmUpWindow, prop.WarmUpWindowDefault)),
+		maxCV:    p.GetFloat64(prop.WarmUpMaxCV, prop.WarmUpMaxCVDefault),
+		minTime:  time.Duration(p.GetInt64(prop.WarmUpTime, 0)) * time.Second,
+		maxTime:  time.Duration(p.GetInt64(prop.WarmUpMaxTime, prop.WarmUpMaxTimeDefault)) * time.Second,
+		hist:     hdrhistogram.New(1, maxWarmUpLatency, 2),
+	}
+	if d.interval <= 0 {
+		util.Fatalf("%s must be positive", prop.WarmUpInterval)
+	}
+	if d.window < 2 {
+		util.Fatalf("%s must be at least 2, but got %d", prop.WarmUpWindow, d.window)
+	}
+	if d.maxCV <= 0 {
+		util.Fatalf("%s must be positive, but got %v", prop.WarmUpMaxCV, d.maxCV)
+	}
+	if d.maxTime < d.minTime {
+		util.Fatalf("%s %s is less than %s %s", prop.WarmUpMaxTime, d.maxTime, prop.WarmUpTime, d.minTime)
+	}
+	return d
+}
+
+func (d *warmUpDetector) observe(lan time.Duration) {
+	v := lan.Microseconds()
+	if v > maxWarmUpLatency {
+		v = maxWarmUpLatency
+	}
+	d.mu.Lock()
+	d.count++
+	d.hist.RecordValue(v)
+	d.mu.Unlock()
+}
+
+// sample closes the interval which lasts elapsed.
+func (d *warmUpDetector) sample(elapsed time.Duration) {
+	d.mu.Lock()
+	ops := float64(d.count) / elapsed.Seconds()
+	p99 := float64(d.hist.ValueAtQuantile(99))
+	d.count = 0
+	d.hist.Reset()
+	d.mu.Unlock()
+
+	d.ops = append(d.ops, ops)
+	d.p99s = append(d.p99s, p99)
+	if len(d.ops) > d.window {
+		d.ops = d.ops[1:]
+		d.p99s = d.p99s[1:]
+	}
+}
+
+// steady returns the coefficients of variation of the throughput and the
+// 99th percentile latency, and whether both are under the threshold over a
+// full window.
+func (d *warmUpDetector) steady() (float64, float64, bool) {
+	cvOPS, cvP99 := coefficientOfVariation(d.ops), coefficientOfVariation(d.p99s)
+	return cvOPS, cvP99, len(d.ops) == d.window && cvOPS <= d.maxCV && cvP99 <= d.maxCV
+}
+
+func coefficientOfVariation(xs []float64) float64 {
+	if len(xs) == 0 {
+		return math.Inf(1)
+	}
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	mean := sum / float64(len(xs))
+	if mean == 0 {
+		// all zero
+		return 0
+	}
+	var variance float64
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(variance/float64(len(xs))) / mean
+}
+
+// wait blocks until warm-up is over, and returns how long it takes, or false
+// if the run is canceled.
+func (d *warmUpDetector) wait(ctx context.Context) (time.Duration, bool) {
+	start := time.Now()
+	last := start
+	t := time.NewTicker(d.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, false
+		case now := <-t.C:
+			d.sample(now.Sub(last))
+			last = now
+			elapsed := now.Sub(start)
+			cvOPS, cvP99, steady := d.steady()
+			if elapsed >= d.maxTime {
+				fmt.Printf("Warm-up reaches %s %s, takes %s, CV of OPS: %.3f, CV of 99th: %.3f\n",
+					prop.WarmUpMaxTime, d.maxTime, elapsed.Round(time.Millisecond), cvOPS, cvP99)
+				return elapsed, true
+			}
+			if steady && elapsed >= d.minTime {
+				fmt.Printf("Warm-up is steady, takes %s, CV of OPS: %.3f, CV of 99th: %.3f\n",
+					elapsed.Round(time.Millisecond), cvOPS, cvP99)
+				return elapsed, true
+			}
+		}
+	}
+}
+
+var (
+	currentWarmUp      atomic.Value
+	warmUpObserverOnce sync.Once
+)
+
+// warmUpObserver passes the operations to the detector of the running
+// client, it's added once as the observers can't be removed. The operations
+// measured again from their intended start times aren't counted twice.
+type warmUpObserver struct{}
+
+func (warmUpObserver) Observe(op string, _ time.Time, lan time.Duration) {
+	if strings.HasPrefix(op, intendedPrefix) {
+		return
+	}
+	if d, ok := currentWarmUp.Load().(*warmUpDetector); ok && d != nil {
+		d.observe(lan)
+	}
+}
+
+func setWarmUpDetector(d *warmUpDetector) {
+	if d != nil {
+		warmUpObserverOnce.Do(func() {
+			measurement.AddObserver(warmUpObserver{})
+		})
+	}
+	currentWarmUp.Store(d)
+}
@@ -0,0 +1,41 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+func TestWarmUpDetector(t *testing.T) {
+	p := properties.NewProperties()
+	p.Set(prop.WarmUpWindow, "3")
+	p.Set(prop.WarmUpMaxCV, "0.05")
+	d := newWarmUpDetector(p)
+
+	// the throughput climbs and then settles
+	for i, ops := range []int{100, 500, 1000, 1010, 990} {
+		for j := 0; j < ops; j++ {
+			d.observe(time.Duration(100+j%10) * time.Microsecond)
+		}
+		d.sample(time.Second)
+		cvOPS, _, steady := d.steady()
+		if steady != (i == 4) {
+			t.Fatalf("interval %d: want steady %v, got %v with CV of OPS %v", i, i == 4, steady, cvOPS)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dbtest has the conformance tests shared by the database bindings,
+// a binding test builds its DB and calls the tests it supports.
+package dbtest
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	// register closedeconomy
+	_ "github.com/pingcap/go-ycsb/pkg/workload"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// RangeScanTest checks the bounds, the order and the limit of RangeScan, the
+// DB must have field0 and an empty usertable.
+func RangeScanTest(t *testing.T, db ycsb.DB) {
+	ctx := context.Background()
+	for _, key := range []string{"user1", "user2", "user3", "user4", "user5"} {
+		if err := db.Insert(ctx, "usertable", key, map[string][]byte{"field0": []byte(key)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		startKey string
+		endKey   string
+		reverse  bool
+		limit    int
+		want     []string
+	}{
+		// the start key is inclusive
+		{startKey: "user2", limit: 2, want: []string{"user2", "user3"}},
+		// the end key is exclusive
+		{startKey: "user2", endKey: "user4", limit: 10, want: []string{"user2", "user3"}},
+		{endKey: "user3", limit: 10, want: []string{"user1", "user2"}},
+		// a reverse scan goes down from before the end key
+		{endKey: "user4", reverse: true, limit: 2, want: []string{"user3", "user2"}},
+		{startKey: "user2", endKey: "user5", reverse: true, limit: 10, want: []string{"user4", "user3", "user2"}},
+		{reverse: true, limit: 2, want: []string{"user5", "user4"}},
+		{startKey: "user4", endKey: "user2", limit: 10},
+	}
+	for _, tt := range tests {
+		rows, err := db.(ycsb.RangeScanDB).RangeScan(ctx, "usertable", tt.startKey, tt.endKey, tt.reverse, tt.limit, []string{"field0"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, row := range rows {
+			got = append(got, string(row["field0"]))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("scan [%q, %q) reverse %v limit %d: want %v, but got %v", tt.startKey, tt.endKey, tt.reverse, tt.limit, tt.want, got)
+		}
+	}
+}
+
+// TxnTest checks a transaction reads its own writes, and they're only seen
+// after it commits, the DB must have field0 and an empty usertable.
+func TxnTest(t *testing.T, db ycsb.DB) {
+	ctx := context.Background()
+	if err := db.Insert(ctx, "usertable", "user1", map[string][]byte{"field0": []byte("a")}); err != nil {
+		t.Fatal(err)
+	}
+	read := func(r func(context.Context, string, string, []string) (map[string][]byte, error)) string {
+		values, err := r(ctx, "usertable", "user1", []string{"field0"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(values["field0"])
+	}
+
+	for _, commit := range []bool{false, true} {
+		txn, err := db.(ycsb.TxnDB).Begin(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := txn.Write(ctx, "usertable", "user1", map[string][]byte{"field0": []byte("b")}); err != nil {
+			t.Fatal(err)
+		}
+		// the transaction reads its own write
+		if v := read(txn.Read); v != "b" {
+			t.Fatalf("want b in the transaction, but got %s", v)
+		}
+		want := "a"
+		if commit {
+			err, want = txn.Commit(ctx), "b"
+		} else {
+			err = txn.Abort(ctx)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := read(db.Read); v != want {
+			t.Fatalf("want %s after the transaction ends with commit %v, but got %s", want, commit, v)
+		}
+	}
+}
+
+// ClosedEconomyTest runs concurrent closedeconomy transfers and validates
+// the money is conserved, the DB must have an empty usertable.
+func ClosedEconomyTest(t *testing.T, db ycsb.DB) {
+	p := properties.NewProperties()
+	p.Set(prop.RecordCount, "20")
+	p.Set(prop.InsertStart, "10")
+	measurement.InitMeasure(p)
+	w, err := ycsb.GetWorkloadCreator("closedeconomy").Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := w.InitThread(context.Background(), 0, 1)
+	for i := 0; i < 10; i++ {
+		if err := w.DoInsert(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		ctx := w.InitThread(context.Background(), i, 4)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := w.DoTransaction(ctx, db); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := w.(ycsb.ValidatedWorkload).Validate(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,595 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The consistency models the checker supports, every one is checked per key.
+const (
+	// Linearizable means every operation takes effect at a point between its
+	// invocation and completion.
+	Linearizable = "linearizable"
+	// ReadYourWrites means a thread never reads a value older than the value it
+	// writes before.
+	ReadYourWrites = "read-your-writes"
+	// MonotonicReads means a thread never reads a value older than a value it
+	// reads before.
+	MonotonicReads = "monotonic-reads"
+)
+
+// maxSearchStates is the most states searched for a linearization of the
+// operations between two quiescent points of a key, beyond which the key is
+// reported as unknown.
+const maxSearchStates = 1 << 20
+
+// Violation is an anomaly found in a history.
+type Violation struct {
+	Table  string
+	Key    string
+	Reason string
+	// Events are the operations of the anomaly.
+	Events []*Event
+}
+
+// Result is the result of checking a history against a model.
+type Result struct {
+	Model      string
+	Keys       int
+	Events     int
+	Violations []*Violation
+	// Unknown are the keys whose histories are too concurrent to check.
+	Unknown []string
+}
+
+type recordKey struct {
+	table string
+	key   string
+}
+
+// Checker checks a history against the consistency models.
+type Checker struct {
+	keys   map[recordKey][]*Event
+	order  []recordKey
+	events int
+	unique *bool
+}
+
+// NewChecker returns an empty checker.
+func NewChecker() *Checker {
+	return &Checker{keys: make(map[recordKey][]*Event)}
+}
+
+// Add adds an event of the history.
+func (c *Checker) Add(e *Event) {
+	k := recordKey{table: e.Table, key: e.Key}
+	if _, ok := c.keys[k]; !ok {
+		c.order = append(c.order, k)
+	}
+	c.keys[k] = append(c.keys[k], e)
+	c.events++
+	c.unique = nil
+}
+
+// UniqueValues returns whether no value is written to a field of a record
+// twice. If not, like with dataintegrity, a read can't tell which write it
+// observes, so a read of a value written later is taken as a read of the
+// value before the history instead of a violation.
+func (c *Checker) UniqueValues() bool {
+	if c.unique != nil {
+		return *c.unique
+	}
+	unique := true
+	for _, events := range c.keys {
+		written := make(map[string]map[string]struct{})
+		for _, e := range events {
+			if !e.isWrite() {
+				continue
+			}
+			for field, h := range e.Values {
+				if _, ok := written[field][h]; ok {
+					unique = false
+					break
+				}
+				if written[field] == nil {
+					written[field] = make(map[string]struct{})
+				}
+				written[field][h] = struct{}{}
+			}
+		}
+		if !unique {
+			break
+		}
+	}
+	c.unique = &unique
+	return unique
+}
+
+// Check checks the history against the model.
+func (c *Checker) Check(model string) (*Result, error) {
+	var check func(k recordKey, events []*Event, res *Result)
+	switch model {
+	case Linearizable:
+		check = c.checkLinearizable
+	case ReadYourWrites:
+		check = c.checkReadYourWrites
+	case MonotonicReads:
+		check = c.checkMonotonicReads
+	default:
+		return nil, fmt.Errorf("unknown model %s, must be %s, %s or %s", model, Linearizable, ReadYourWrites, MonotonicReads)
+	}
+
+	res := &Result{Model: model, Keys: len(c.order), Events: c.events}
+	for _, k := range c.order {
+		events := c.keys[k]
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].Invoke < events[j].Invoke
+		})
+		check(k, events, res)
+	}
+	return res, nil
+}
+
+// happensBefore returns whether a completes before b is invoked, a failed
+// write may take effect at any time later.
+func happensBefore(a *Event, b *Event) bool {
+	return a.Error == "" && a.Complete < b.Invoke
+}
+
+// fieldHistory is the values written to the fields of a record, a delete
+// writes the empty value to all fields.
+type fieldHistory struct {
+	fields []string
+	writes map[string]map[string][]*Event
+}
+
+func newFieldHistory(events []*Event) *fieldHistory {
+	h := &fieldHistory{writes: make(map[string]map[string][]*Event)}
+	seen := make(map[string]struct{})
+	for _, e := range events {
+		for field := range e.Values {
+			if _, ok := seen[field]; !ok {
+				seen[field] = struct{}{}
+				h.fields = append(h.fields, field)
+			}
+		}
+	}
+	sort.Strings(h.fields)
+
+	for _, e := range events {
+		if !e.isWrite() {
+			continue
+		}
+		for field, v := range h.valuesOf(e) {
+			if h.writes[field] == nil {
+				h.writes[field] = make(map[string][]*Event)
+			}
+			h.writes[field][v] = append(h.writes[field][v], e)
+		}
+	}
+	return h
+}
+
+// valuesOf returns the values of the fields written or observed by the event,
+// the fields of a missing record have the empty value.
+func (h *fieldHistory) valuesOf(e *Event) map[string]string {
+	switch {
+	case e.Op == OpUpdate || (e.Op == OpRead && len(e.Values) > 0):
+		return e.Values
+	case e.Op == OpInsert:
+		values := make(map[string]string, len(h.fields))
+		for _, field := range h.fields {
+			values[field] = e.Values[field]
+		}
+		return values
+	default:
+		values := make(map[string]string, len(h.fields))
+		for _, field := range h.fields {
+			values[field] = ""
+		}
+		return values
+	}
+}
+
+// older returns whether the value v of the field is older than the value
+// written by the writes, which is when every write of v happens before
+// every one of them, or v isn't written in the history.
+func (h *fieldHistory) older(field string, v string, writes []*Event) bool {
+	if len(writes) == 0 {
+		return false
+	}
+	vWrites := h.writes[field][v]
+	if len(vWrites) == 0 {
+		return true
+	}
+	for _, a := range vWrites {
+		for _, b := range writes {
+			if a == b || !happensBefore(a, b) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func byThread(events []*Event) ([]int, map[int][]*Event) {
+	var threads []int
+	m := make(map[int][]*Event)
+	for _, e := range events {
+		if _, ok := m[e.Thread]; !ok {
+			threads = append(threads, e.Thread)
+		}
+		m[e.Thread] = append(m[e.Thread], e)
+	}
+	return threads, m
+}
+
+func (c *Checker) checkReadYourWrites(k recordKey, events []*Event, res *Result) {
+	h := newFieldHistory(events)
+	threads, m := byThread(events)
+	for _, thread := range threads {
+		lastWrite := make(map[string]*Event)
+		for _, e := range m[thread] {
+			if e.Error != "" {
+				continue
+			}
+			values := h.valuesOf(e)
+			if e.isWrite() {
+				for field := range values {
+					lastWrite[field] = e
+				}
+				continue
+			}
+			for _, field := range sortedFields(values) {
+				w, ok := lastWrite[field]
+				if !ok || h.valuesOf(w)[field] == values[field] {
+					continue
+				}
+				if h.older(field, values[field], []*Event{w}) {
+					res.Violations = append(res.Violations, &Violation{
+						Table:  k.table,
+						Key:    k.key,
+						Reason: fmt.Sprintf("thread %d reads a value of %s older than it writes", thread, field),
+						Events: []*Event{w, e},
+					})
+					break
+				}
+			}
+		}
+	}
+}
+
+func (c *Checker) checkMonotonicReads(k recordKey, events []*Event, res *Result) {
+	h := newFieldHistory(events)
+	threads, m := byThread(events)
+	for _, thread := range threads {
+		// the distinct values read of every field, and the first read of them.
+		read := make(map[string]map[string]*Event)
+	next:
+		for _, e := range m[thread] {
+			if e.isWrite() || e.Error != "" {
+				continue
+			}
+			values := h.valuesOf(e)
+			for _, field := range sortedFields(values) {
+				v := values[field]
+				for u, prev := range read[field] {
+					if u != v && h.older(field, v, h.writes[field][u]) {
+						res.Violations = append(res.Violations, &Violation{
+							Table:  k.table,
+							Key:    k.key,
+							Reason: fmt.Sprintf("thread %d reads a value of %s older than it reads before", thread, field),
+							Events: []*Event{prev, e},
+						})
+						continue next
+					}
+				}
+			}
+			for field, v := range values {
+				if read[field] == nil {
+					read[field] = make(map[string]*Event)
+				}
+				if _, ok := read[field][v]; !ok {
+					read[field][v] = e
+				}
+			}
+		}
+	}
+}
+
+func sortedFields(values map[string]string) []string {
+	fields := make([]string, 0, len(values))
+	for field := range values {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// The existence of a record in a register state.
+const (
+	existsUnknown = iota
+	existsYes
+	existsNo
+)
+
+// regState is the state of a record in the search of a linearization, the
+// fields not known yet are the values before the history.
+type regState struct {
+	exists int
+	// fresh means the record is inserted or deleted in the history, so the
+	// fields not written are absent instead of unknown.
+	fresh  bool
+	fields map[string]string
+	enc    string
+}
+
+func newRegState(exists int, fresh bool, fields map[string]string) *regState {
+	s := &regState{exists: exists, fresh: fresh, fields: fields}
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(exists))
+	b.WriteString(strconv.FormatBool(fresh))
+	for _, field := range sortedFields(fields) {
+		b.WriteString(";")
+		b.WriteString(field)
+		b.WriteString("=")
+		b.WriteString(fields[field])
+	}
+	s.enc = b.String()
+	return s
+}
+
+func mergeFields(fields map[string]string, values map[string]string) map[string]string {
+	merged := make(map[string]string, len(fields)+len(values))
+	for field, v := range fields {
+		merged[field] = v
+	}
+	for field, v := range values {
+		merged[field] = v
+	}
+	return merged
+}
+
+// linOp is an operation in the search of a linearization.
+type linOp struct {
+	e  *Event
+	id int
+	// indeterminate ops are the failed writes, which may take effect at any
+	// time after they are invoked, or never.
+	indeterminate bool
+	complete      int64
+}
+
+// linConfig is a state of the record and the indeterminate ops which don't
+// take effect yet.
+type linConfig struct {
+	s       *regState
+	pending []*linOp
+}
+
+func (c linConfig) key() string {
+	var b strings.Builder
+	b.WriteString(c.s.enc)
+	for _, o := range c.pending {
+		b.WriteString("|")
+		b.WriteString(strconv.Itoa(o.id))
+	}
+	return b.String()
+}
+
+// linSearch searches all linearizations of the ops between two quiescent
+// points, from a state of the record.
+type linSearch struct {
+	ops  []*linOp
+	done []bool
+	// written are the values written to the fields of the record.
+	written map[string]map[string]struct{}
+	unique  bool
+
+	seen     map[string]struct{}
+	out      map[string]linConfig
+	tooLarge bool
+}
+
+func (s *linSearch) run(st *regState) {
+	if s.tooLarge {
+		return
+	}
+	var b strings.Builder
+	for _, d := range s.done {
+		if d {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+	}
+	b.WriteString(st.enc)
+	key := b.String()
+	if _, ok := s.seen[key]; ok {
+		return
+	}
+	if len(s.seen) >= maxSearchStates {
+		s.tooLarge = true
+		return
+	}
+	s.seen[key] = struct{}{}
+
+	// an op can take effect next unless another op completes before it's
+	// invoked.
+	minComplete := int64(math.MaxInt64)
+	finished := true
+	for i, o := range s.ops {
+		if !s.done[i] && !o.indeterminate {
+			finished = false
+			if o.complete < minComplete {
+				minComplete = o.complete
+			}
+		}
+	}
+	if finished {
+		c := linConfig{s: st}
+		for i, o := range s.ops {
+			if !s.done[i] {
+				c.pending = append(c.pending, o)
+			}
+		}
+		s.out[c.key()] = c
+		return
+	}
+
+	for i, o := range s.ops {
+		if s.done[i] || o.e.Invoke > minComplete {
+			continue
+		}
+		for _, next := range s.step(st, o.e) {
+			s.done[i] = true
+			s.run(next)
+			s.done[i] = false
+		}
+	}
+}
+
+// step returns the states after the operation takes effect on the state,
+// none if it can't.
+func (s *linSearch) step(st *regState, e *Event) []*regState {
+	switch e.Op {
+	case OpRead:
+		if len(e.Values) == 0 {
+			if st.exists == existsYes {
+				return nil
+			}
+			return []*regState{newRegState(existsNo, true, nil)}
+		}
+		if st.exists == existsNo {
+			return nil
+		}
+		var bound map[string]string
+		for field, v := range e.Values {
+			if cur, ok := st.fields[field]; ok {
+				if cur != v {
+					return nil
+				}
+				continue
+			}
+			// the value before the history isn't any value written in it.
+			if _, ok := s.written[field][v]; st.fresh || (ok && s.unique) {
+				return nil
+			}
+			if bound == nil {
+				bound = make(map[string]string)
+			}
+			bound[field] = v
+		}
+		if bound == nil && st.exists == existsYes {
+			return []*regState{st}
+		}
+		return []*regState{newRegState(existsYes, st.fresh, mergeFields(st.fields, bound))}
+	case OpInsert:
+		return []*regState{newRegState(existsYes, true, mergeFields(nil, e.Values))}
+	case OpUpdate:
+		updated := newRegState(existsYes, st.fresh, mergeFields(st.fields, e.Values))
+		switch st.exists {
+		case existsNo:
+			// an update of a missing record may insert it or do nothing.
+			return []*regState{st, updated}
+		case existsUnknown:
+			return []*regState{newRegState(existsNo, true, nil), updated}
+		default:
+			return []*regState{updated}
+		}
+	default:
+		return []*regState{newRegState(existsNo, true, nil)}
+	}
+}
+
+func (c *Checker) checkLinearizable(k recordKey, events []*Event, res *Result) {
+	written := make(map[string]map[string]struct{})
+	ops := make([]*linOp, 0, len(events))
+	for _, e := range events {
+		if e.isWrite() {
+			for field, v := range e.Values {
+				if written[field] == nil {
+					written[field] = make(map[string]struct{})
+				}
+				written[field][v] = struct{}{}
+			}
+		} else if e.Error != "" {
+			// a failed read observes nothing.
+			continue
+		}
+		o := &linOp{e: e, id: len(ops), complete: e.Complete}
+		if e.Error != "" {
+			o.indeterminate = true
+			o.complete = math.MaxInt64
+		}
+		ops = append(ops, o)
+	}
+
+	unique := c.UniqueValues()
+	configs := []linConfig{{s: newRegState(existsUnknown, false, nil)}}
+	for start := 0; start < len(ops); {
+		// the ops between two quiescent points, when no op is in progress.
+		end := start + 1
+		maxComplete := ops[start].complete
+		if ops[start].indeterminate {
+			maxComplete = math.MinInt64
+		}
+		for ; end < len(ops) && ops[end].e.Invoke <= maxComplete; end++ {
+			if !ops[end].indeterminate && ops[end].complete > maxComplete {
+				maxComplete = ops[end].complete
+			}
+		}
+
+		out := make(map[string]linConfig)
+		for _, cfg := range configs {
+			s := &linSearch{
+				ops:     append(append([]*linOp(nil), cfg.pending...), ops[start:end]...),
+				written: written,
+				unique:  unique,
+				seen:    make(map[string]struct{}),
+				out:     out,
+			}
+			s.done = make([]bool, len(s.ops))
+			s.run(cfg.s)
+			if s.tooLarge {
+				res.Unknown = append(res.Unknown, fmt.Sprintf("%s/%s", k.table, k.key))
+				return
+			}
+		}
+		if len(out) == 0 {
+			segment := make([]*Event, 0, end-start)
+			for _, o := range ops[start:end] {
+				segment = append(segment, o.e)
+			}
+			res.Violations = append(res.Violations, &Violation{
+				Table:  k.table,
+				Key:    k.key,
+				Reason: fmt.Sprintf("no linearization of the %d operations from %d ns", end-start, ops[start].e.Invoke),
+				Events: segment,
+			})
+			return
+		}
+
+		configs = configs[:0]
+		for _, cfg := range out {
+			configs = append(configs, cfg)
+		}
+		start = end
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"testing"
+)
+
+func write(thread int, invoke, complete int64, v string) *Event {
+	return &Event{Thread: thread, Op: OpUpdate, Table: "t", Key: "k", Invoke: invoke, Complete: complete,
+		Values: map[string]string{"field0": v}}
+}
+
+func read(thread int, invoke, complete int64, v string) *Event {
+	e := &Event{Thread: thread, Op: OpRead, Table: "t", Key: "k", Invoke: invoke, Complete: complete}
+	if v != "" {
+		e.Values = map[string]string{"field0": v}
+	}
+	return e
+}
+
+func checkViolations(t *testing.T, events []*Event, model string) int {
+	c := NewChecker()
+	for _, e := range events {
+		c.Add(e)
+	}
+	res, err := c.Check(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Unknown) > 0 {
+		t.Fatalf("unknown keys %v", res.Unknown)
+	}
+	return len(res.Violations)
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []*Event
+		// the number of violations of every model.
+		linearizable, readYourWrites, monotonicReads int
+	}{
+		{
+			name: "concurrent writes and reads",
+			events: []*Event{
+				read(0, 0, 10, "a"),
+				write(1, 20, 60, "b"),
+				write(2, 30, 50, "c"),
+				read(0, 40, 45, "c"),
+				read(3, 55, 58, "b"),
+				read(0, 70, 80, "b"),
+			},
+		},
+		{
+			name: "stale read of another thread",
+			events: []*Event{
+				write(1, 0, 10, "a"),
+				write(1, 20, 30, "b"),
+				read(2, 40, 50, "a"),
+			},
+			linearizable: 1,
+		},
+		{
+			name: "stale read of its own write",
+			events: []*Event{
+				write(1, 0, 10, "a"),
+				write(2, 20, 30, "b"),
+				read(2, 40, 50, "a"),
+			},
+			linearizable:   1,
+			readYourWrites: 1,
+		},
+		{
+			name: "read the value before the history after a write",
+			events: []*Event{
+				read(1, 0, 10, "a"),
+				write(2, 20, 30, "b"),
+				read(1, 40, 50, "b"),
+				read(1, 60, 70, "a"),
+			},
+			linearizable:   1,
+			monotonicReads: 1,
+		},
+		{
+			name: "failed write takes effect later",
+			events: []*Event{
+				write(1, 0, 10, "a"),
+				{Thread: 2, Op: OpUpdate, Table: "t", Key: "k", Invoke: 20, Complete: 30,
+					Values: map[string]string{"field0": "b"}, Error: "timeout"},
+				read(1, 40, 50, "a"),
+				read(1, 60, 70, "b"),
+			},
+		},
+		{
+			name: "read a deleted record",
+			events: []*Event{
+				write(1, 0, 10, "a"),
+				{Thread: 1, Op: OpDelete, Table: "t", Key: "k", Invoke: 20, Complete: 30},
+				read(1, 40, 50, ""),
+				read(2, 60, 70, "a"),
+			},
+			linearizable: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		if n := checkViolations(t, tt.events, Linearizable); n != tt.linearizable {
+			t.Errorf("%s: want %d linearizability violations, got %d", tt.name, tt.linearizable, n)
+		}
+		if n := checkViolations(t, tt.events, ReadYourWrites); n != tt.readYourWrites {
+			t.Errorf("%s: want %d read-your-writes violations, got %d", tt.name, tt.readYourWrites, n)
+		}
+		if n := checkViolations(t, tt.events, MonotonicReads); n != tt.monotonicReads {
+			t.Errorf("%s: want %d monotonic reads violations, got %d", tt.name, tt.monotonicReads, n)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package history records the history of a run, which is when every read and
+// write is invoked and completes, by which thread, and the values written or
+// observed, so the consistency of the database can be checked offline.
+//
+// A history has a JSON event per line, e.g.
+//
+//	{"thread":3,"op":"UPDATE","table":"usertable","key":"user1","invoke_ns":1200,"complete_ns":2500,"values":{"field0":"af63dc4c8601ec8c"}}
+//
+// The values are recorded as their FNV-1a hashes to keep the history small.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// The operations in a history.
+const (
+	OpRead   = "READ"
+	OpUpdate = "UPDATE"
+	OpInsert = "INSERT"
+	OpDelete = "DELETE"
+)
+
+// Event is an operation in a history.
+type Event struct {
+	// Thread is the thread which does the operation, -1 if it's done out of a
+	// worker, like in the shell.
+	Thread int    `json:"thread"`
+	Op     string `json:"op"`
+	Table  string `json:"table"`
+	Key    string `json:"key"`
+	// Invoke and Complete are when the operation is invoked and completes
+	// since the history starts in ns.
+	Invoke   int64 `json:"invoke_ns"`
+	Complete int64 `json:"complete_ns"`
+	// Values are the hashes of the values written by a write or observed by a
+	// read, a read without values doesn't find the record.
+	Values map[string]string `json:"values,omitempty"`
+	// Error is the error of a failed operation, a failed write may take effect
+	// or not.
+	Error string `json:"error,omitempty"`
+}
+
+func (e *Event) validate() error {
+	switch e.Op {
+	case OpRead, OpUpdate, OpInsert, OpDelete:
+	default:
+		return fmt.Errorf("unknown op %q", e.Op)
+	}
+	if e.Key == "" {
+		return fmt.Errorf("no key")
+	}
+	if e.Complete < e.Invoke {
+		return fmt.Errorf("completes at %d before invoked at %d", e.Complete, e.Invoke)
+	}
+	return nil
+}
+
+func (e *Event) isWrite() bool {
+	return e.Op != OpRead
+}
+
+// HashValue returns the hash of a value recorded in a history.
+func HashValue(v []byte) string {
+	h := fnv.New64a()
+	h.Write(v)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// HashValues returns the hashes of the values, or nil if there are no values.
+func HashValues(values map[string][]byte) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+	hashes := make(map[string]string, len(values))
+	for field, v := range values {
+		hashes[field] = HashValue(v)
+	}
+	return hashes
+}
+
+// Reader reads the events of a history.
+type Reader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// NewReader returns a reader of the history in r.
+func NewReader(r io.Reader) *Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &Reader{s: s}
+}
+
+// Next returns the next event, or io.EOF at the end of the history.
+func (r *Reader) Next() (*Event, error) {
+	for r.s.Scan() {
+		r.line++
+		line := r.s.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		e := new(Event)
+		if err := json.Unmarshal(line, e); err != nil {
+			return nil, fmt.Errorf("line %d: %v", r.line, err)
+		}
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %v", r.line, err)
+		}
+		return e, nil
+	}
+	if err := r.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// recorder writes the events of a run.
+type recorder struct {
+	start time.Time
+
+	mu  sync.Mutex
+	f   *os.File
+	w   *bufio.Writer
+	enc *json.Encoder
+	err error
+}
+
+var globalRecorder *recorder
+
+// Init starts recording the history if history.file is set.
+func Init(p *properties.Properties) {
+	path := p.GetString(prop.HistoryFile, "")
+	if path == "" {
+		return
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		util.Fatalf("create history %s failed %v", path, err)
+	}
+	w := bufio.NewWriterSize(f, 64*1024)
+	globalRecorder = &recorder{
+		start: time.Now(),
+		f:     f,
+		w:     w,
+		enc:   json.NewEncoder(w),
+	}
+}
+
+// Enabled returns whether the history is recorded.
+func Enabled() bool {
+	return globalRecorder != nil
+}
+
+// Log records the operation which is invoked at invoke and completes at
+// complete, its times are set by the recorder.
+func Log(invoke time.Time, complete time.Time, e *Event) {
+	l := globalRecorder
+	if l == nil {
+		return
+	}
+	e.Invoke = invoke.Sub(l.start).Nanoseconds()
+	e.Complete = complete.Sub(l.start).Nanoseconds()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.enc == nil || l.err != nil {
+		return
+	}
+	if l.err = l.enc.Encode(e); l.err != nil {
+		fmt.Fprintf(os.Stderr, "history: write failed %v, stop recording\n", l.err)
+	}
+}
+
+// Close flushes and closes the history.
+func Close() {
+	l := globalRecorder
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.enc == nil {
+		return
+	}
+	l.enc = nil
+	if err := l.w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "history: flush failed %v\n", err)
+	}
+	if err := l.f.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "history: close failed %v\n", err)
+	}
+}
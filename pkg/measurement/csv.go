@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"io"
 	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
 )
 
 type csventry struct {
@@ -15,23 +19,71 @@ type csventry struct {
 
 type csvs struct {
 	opCsv map[string][]csventry
+
+	// stream is set when the samples are written to measurement.output_file
+	// while measuring instead of kept in memory.
+	stream *csvStream
+	batch  []csvrecord
+}
+
+// InitCSV creates the raw measurement. If measurement.output_file is set,
+// the samples are streamed to the file, otherwise they are kept in memory
+// and written on output.
+func InitCSV(p *properties.Properties) *csvs {
+	path := p.GetString(prop.MeasurementRawOutputFile, "")
+	if path == "" {
+		return &csvs{
+			opCsv: make(map[string][]csventry),
+		}
+	}
+
+	compress := p.GetBool(prop.MeasurementRawCompress, prop.MeasurementRawCompressDefault)
+	rotateSize := p.GetInt64(prop.MeasurementRawRotateSize, prop.MeasurementRawRotateSizeDefault)
+	s, err := newCSVStream(path, compress, rotateSize<<20)
+	if err != nil {
+		panic("failed to create raw output file: " + err.Error())
+	}
+	return newStreamCSV(s)
 }
 
-func InitCSV() *csvs {
+func newStreamCSV(s *csvStream) *csvs {
 	return &csvs{
-		opCsv: make(map[string][]csventry),
+		stream: s,
+		batch:  make([]csvrecord, 0, csvBatchSize),
 	}
 }
 
 func (c *csvs) Measure(op string, start time.Time, lan time.Duration) {
-	c.opCsv[op] = append(c.opCsv[op], csventry{
+	entry := csventry{
 		startUs:   start.UnixMicro(),
 		latencyUs: lan.Microseconds(),
-	})
+	}
+	if c.stream == nil {
+		c.opCsv[op] = append(c.opCsv[op], entry)
+		return
+	}
+
+	c.batch = append(c.batch, csvrecord{op: op, csventry: entry})
+	if len(c.batch) == csvBatchSize {
+		c.sendBatch()
+	}
+}
+
+func (c *csvs) sendBatch() {
+	if len(c.batch) == 0 {
+		return
+	}
+	c.stream.send(c.batch)
+	c.batch = make([]csvrecord, 0, csvBatchSize)
 }
 
 func (c *csvs) Output(w io.Writer) error {
-	_, err := fmt.Fprintln(w, "operation,timestamp_us,latency_us")
+	if c.stream != nil {
+		// the samples are already in the output file
+		return nil
+	}
+
+	_, err := fmt.Fprint(w, csvHeader)
 	if err != nil {
 		return err
 	}
@@ -47,5 +99,33 @@ func (c *csvs) Output(w io.Writer) error {
 }
 
 func (c *csvs) Summary() {
-	// do nothing as csvs don't keep a summary
+	if c.stream == nil {
+		// do nothing as csvs don't keep a summary
+		return
+	}
+	// write the samples so far to the file, which keeps them if the client crashes
+	c.sendBatch()
+	c.stream.send(nil)
+}
+
+func (c *csvs) finish(_ time.Time) {
+	if c.stream != nil {
+		c.sendBatch()
+	}
+}
+
+func (c *csvs) nextSection() ycsb.Measurer {
+	if c.stream == nil {
+		return &csvs{opCsv: make(map[string][]csventry)}
+	}
+	// the sections share the file
+	return newStreamCSV(c.stream)
+}
+
+func (c *csvs) closeStream() error {
+	if c.stream == nil {
+		return nil
+	}
+	c.sendBatch()
+	return c.stream.closeStream()
 }
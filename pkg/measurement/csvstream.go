@@ -0,0 +1,192 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	csvHeader = "operation,timestamp_us,latency_us\n"
+	// csvBatchSize is the number of samples sent to the writer at once.
+	csvBatchSize = 1024
+	// csvQueueSize is the number of batches queued for the writer, the
+	// measuring blocks when the writer falls that far behind.
+	csvQueueSize = 64
+)
+
+type csvrecord struct {
+	op string
+	csventry
+}
+
+// csvStream writes the samples to the output file in a background goroutine,
+// so they don't have to be kept in memory. A nil batch asks the writer to
+// flush what it has written to the file.
+type csvStream struct {
+	ch   chan []csvrecord
+	done chan struct{}
+	err  error
+	// closed is set when the stream is closed, the batches sent after it are
+	// dropped.
+	closed bool
+
+	path     string
+	compress bool
+	// rotateSize is the size of the uncompressed samples in bytes after which
+	// a new file is started, 0 means no rotation.
+	rotateSize int64
+
+	idx     int
+	f       *os.File
+	gz      *gzip.Writer
+	w       *bufio.Writer
+	written int64
+}
+
+func newCSVStream(path string, compress bool, rotateSize int64) (*csvStream, error) {
+	s := &csvStream{
+		ch:         make(chan []csvrecord, csvQueueSize),
+		done:       make(chan struct{}),
+		path:       path,
+		compress:   compress,
+		rotateSize: rotateSize,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	go s.run()
+	return s, nil
+}
+
+// fileName returns the path of the idx-th file, the rotated files have the
+// index inserted before the extension, like raw.1.csv.
+func (s *csvStream) fileName() string {
+	if s.idx == 0 {
+		return s.path
+	}
+	ext := filepath.Ext(s.path)
+	return s.path[:len(s.path)-len(ext)] + "." + strconv.Itoa(s.idx) + ext
+}
+
+func (s *csvStream) open() error {
+	f, err := os.Create(s.fileName())
+	if err != nil {
+		return err
+	}
+	s.f = f
+
+	var w io.Writer = f
+	if s.compress {
+		s.gz = gzip.NewWriter(f)
+		w = s.gz
+	}
+	s.w = bufio.NewWriterSize(w, 1<<20)
+	s.written = 0
+
+	// every file has a header, so a rotated file can be read on its own
+	_, err = s.w.WriteString(csvHeader)
+	return err
+}
+
+func (s *csvStream) flush() error {
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	if s.gz != nil {
+		return s.gz.Flush()
+	}
+	return nil
+}
+
+func (s *csvStream) close() error {
+	if err := s.w.Flush(); err != nil {
+		s.f.Close()
+		return err
+	}
+	if s.gz != nil {
+		if err := s.gz.Close(); err != nil {
+			s.f.Close()
+			return err
+		}
+	}
+	return s.f.Close()
+}
+
+func (s *csvStream) write(batch []csvrecord) error {
+	for _, r := range batch {
+		n, err := fmt.Fprintf(s.w, "%s,%d,%d\n", r.op, r.startUs, r.latencyUs)
+		if err != nil {
+			return err
+		}
+		s.written += int64(n)
+	}
+
+	if s.rotateSize > 0 && s.written >= s.rotateSize {
+		if err := s.close(); err != nil {
+			return err
+		}
+		s.idx++
+		return s.open()
+	}
+	return nil
+}
+
+func (s *csvStream) run() {
+	defer close(s.done)
+
+	for batch := range s.ch {
+		// after a failure the batches are dropped, so the measuring doesn't block
+		if s.err != nil {
+			continue
+		}
+
+		if batch == nil {
+			s.err = s.flush()
+		} else {
+			s.err = s.write(batch)
+		}
+		if s.err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write raw measurement: %v\n", s.err)
+		}
+	}
+
+	if s.err == nil {
+		s.err = s.close()
+	}
+}
+
+func (s *csvStream) send(batch []csvrecord) {
+	if !s.closed {
+		s.ch <- batch
+	}
+}
+
+// closeStream waits for the writer to write all the batches sent and closes
+// the file, it does nothing if the stream is closed.
+func (s *csvStream) closeStream() error {
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+		<-s.done
+	}
+	return s.err
+}
@@ -0,0 +1,156 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+func testRecords(n int) []csvrecord {
+	batch := make([]csvrecord, n)
+	for i := range batch {
+		batch[i] = csvrecord{op: "READ", csventry: csventry{startUs: int64(i), latencyUs: 10}}
+	}
+	return batch
+}
+
+func readCSV(t *testing.T, path string, compress bool) string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if compress {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = gz
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func wantCSV(from int, to int) string {
+	var b strings.Builder
+	b.WriteString(csvHeader)
+	for i := from; i < to; i++ {
+		fmt.Fprintf(&b, "READ,%d,10\n", i)
+	}
+	return b.String()
+}
+
+func TestCSVStream(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "raw.csv")
+		s, err := newCSVStream(path, compress, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records := testRecords(3000)
+		s.send(records[:1000])
+		s.send(records[1000:])
+		if err := s.closeStream(); err != nil {
+			t.Fatal(err)
+		}
+		if got := readCSV(t, path, compress); got != wantCSV(0, 3000) {
+			t.Fatalf("compress %v: unexpected output %q", compress, got)
+		}
+	}
+}
+
+func TestCSVStreamFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raw.csv")
+	s, err := newCSVStream(path, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.closeStream()
+
+	// a nil batch flushes the samples written before it
+	s.send(testRecords(10))
+	s.send(nil)
+	for i := 0; readCSV(t, path, false) != wantCSV(0, 10); i++ {
+		if i == 100 {
+			t.Fatalf("the samples aren't flushed, got %q", readCSV(t, path, false))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCSVStreamRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raw.csv.gz")
+	// a file is started after the batch reaching 100 bytes of samples, which
+	// is every 10 lines of 10 or 11 bytes here
+	s, err := newCSVStream(path, true, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := testRecords(25)
+	for i := 0; i < len(records); i += 5 {
+		s.send(records[i : i+5])
+	}
+	if err := s.closeStream(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Dir(path)
+	files := []string{"raw.csv.gz", "raw.csv.1.gz", "raw.csv.2.gz"}
+	for i, name := range files {
+		to := (i + 1) * 10
+		if to > len(records) {
+			to = len(records)
+		}
+		if got := readCSV(t, filepath.Join(dir, name), true); got != wantCSV(i*10, to) {
+			t.Fatalf("%s: unexpected output %q", name, got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "raw.csv.3.gz")); !os.IsNotExist(err) {
+		t.Fatalf("want 3 files, but got more: %v", err)
+	}
+}
+
+func TestCSVCloseStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raw.csv")
+	p := properties.NewProperties()
+	p.Set(prop.MeasurementRawOutputFile, path)
+	c := InitCSV(p)
+
+	// the samples not sent yet are written on close
+	start := time.UnixMicro(100)
+	c.Measure("READ", start, 10*time.Microsecond)
+	c.Measure("UPDATE", start, 20*time.Microsecond)
+	if err := c.closeStream(); err != nil {
+		t.Fatal(err)
+	}
+	want := csvHeader + "READ,100,10\nUPDATE,100,20\n"
+	if got := readCSV(t, path, false); got != want {
+		t.Fatalf("want %q, but got %q", want, got)
+	}
+}
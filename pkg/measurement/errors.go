@@ -0,0 +1,156 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// topErrorsSection is the name of the section of the top error messages.
+const topErrorsSection = "TOP ERRORS"
+
+// maxErrorMessages limits the distinct error messages counted, the
+// messages after that are counted as otherErrorMessage.
+const (
+	maxErrorMessages  = 1000
+	otherErrorMessage = "(other messages)"
+)
+
+// longNumber matches the numbers like keys, timestamps and transaction IDs
+// in the error messages, but not the short error codes.
+var longNumber = regexp.MustCompile(`[0-9]{5,}`)
+
+var errorHeader = []string{"Operation", "Class", "Count", "Error"}
+
+type errorKey struct {
+	op    string
+	class string
+	msg   string
+}
+
+// errorTracker decides the name the failed operations are measured under,
+// and counts the distinct error messages.
+type errorTracker struct {
+	// eachClass measures every error class separately.
+	eachClass bool
+	// tracked are the error classes measured separately if not eachClass.
+	tracked map[string]bool
+	topN    int
+
+	mu     sync.Mutex
+	counts map[errorKey]int64
+}
+
+func newErrorTracker(p *properties.Properties) *errorTracker {
+	t := &errorTracker{
+		eachClass: p.GetBool(prop.ReportLatencyForEachError, prop.ReportLatencyForEachErrorDefault),
+		tracked:   make(map[string]bool),
+		topN:      p.GetInt(prop.TopErrors, prop.TopErrorsDefault),
+		counts:    make(map[errorKey]int64),
+	}
+	for _, class := range splitList(p.GetString(prop.LatencyTrackedErrors, "")) {
+		if class != "" {
+			t.tracked[strings.ToUpper(class)] = true
+		}
+	}
+	return t
+}
+
+// opName returns the name the failed operation is measured under, it's
+// <OP>_ERROR, or <OP>_<CLASS>_ERROR if the class is tracked.
+func (t *errorTracker) opName(op string, class string) string {
+	if t.eachClass || t.tracked[class] {
+		return op + "_" + class + "_ERROR"
+	}
+	return op + "_ERROR"
+}
+
+func (t *errorTracker) record(op string, class string, msg string) {
+	if t.topN <= 0 {
+		return
+	}
+
+	// the messages which only differ in the keys are counted together
+	key := errorKey{op: op, class: class, msg: longNumber.ReplaceAllString(msg, "#")}
+	t.mu.Lock()
+	if _, ok := t.counts[key]; !ok && len(t.counts) >= maxErrorMessages {
+		key.msg = otherErrorMessage
+	}
+	t.counts[key]++
+	t.mu.Unlock()
+}
+
+// table returns the top N error messages by count.
+func (t *errorTracker) table(outputStyle string) *errorTable {
+	t.mu.Lock()
+	keys := make([]errorKey, 0, len(t.counts))
+	for k := range t.counts {
+		keys = append(keys, k)
+	}
+	counts := make(map[errorKey]int64, len(t.counts))
+	for k, v := range t.counts {
+		counts[k] = v
+	}
+	t.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		if a.op != b.op {
+			return a.op < b.op
+		}
+		return a.msg < b.msg
+	})
+	if len(keys) > t.topN {
+		keys = keys[:t.topN]
+	}
+
+	table := &errorTable{outputStyle: outputStyle}
+	for _, k := range keys {
+		table.rows = append(table.rows, []string{k.op, k.class, util.IntToString(counts[k]), k.msg})
+	}
+	return table
+}
+
+// errorTable is the table of the top error messages in the output.
+type errorTable struct {
+	outputStyle string
+	rows        [][]string
+}
+
+func (t *errorTable) Measure(op string, start time.Time, lan time.Duration) {
+}
+
+func (t *errorTable) Summary() {
+}
+
+func (t *errorTable) Output(w io.Writer) error {
+	renderRows(w, t.outputStyle, errorHeader, t.rows)
+	return nil
+}
+
+func (t *errorTable) summaryRows() ([]string, [][]string) {
+	return errorHeader, t.rows
+}
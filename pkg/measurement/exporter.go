@@ -0,0 +1,217 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// Section is the measurement of a part of the run passed to the exporters.
+type Section struct {
+	// Name is empty if the run isn't split into sections.
+	Name string
+	// Start and End are when the section is measured, they are zero for the
+	// sections which aren't measured, like the top errors.
+	Start time.Time
+	End   time.Time
+	// Header and Rows are the summary of every operation sorted by the
+	// operation, they are empty if the measurement type doesn't keep a summary.
+	Header []string
+	Rows   [][]string
+	// Info is the metrics of every operation like Info, it's nil if the
+	// measurement type doesn't keep the metrics.
+	Info map[string]map[string]interface{}
+
+	measurer ycsb.Measurer
+}
+
+// Output writes the section in the format of the measurement type.
+func (s Section) Output(w io.Writer) error {
+	return s.measurer.Output(w)
+}
+
+func newSection(sec section) Section {
+	s := Section{Name: sec.name, Start: sec.start, End: sec.end, measurer: sec.measurer}
+	if r, ok := sec.measurer.(summarizer); ok {
+		s.Header, s.Rows = r.summaryRows()
+	}
+	if i, ok := sec.measurer.(infoMeasurer); ok {
+		s.Info = i.info()
+	}
+	return s
+}
+
+// Exporter writes the final report of the measurement.
+type Exporter interface {
+	// Export writes the sections to w.
+	Export(w io.Writer, sections []Section) error
+}
+
+// ExporterCreator creates an exporter.
+type ExporterCreator interface {
+	Create(p *properties.Properties) (Exporter, error)
+}
+
+var exporterCreators = map[string]ExporterCreator{}
+
+// RegisterExporterCreator registers a creator for the exporter
+func RegisterExporterCreator(name string, creator ExporterCreator) {
+	_, ok := exporterCreators[name]
+	if ok {
+		panic(fmt.Sprintf("duplicate register exporter %s", name))
+	}
+
+	exporterCreators[name] = creator
+}
+
+// GetExporterCreator gets the ExporterCreator for the exporter
+func GetExporterCreator(name string) ExporterCreator {
+	return exporterCreators[name]
+}
+
+// textExporter writes the sections in the format of the measurement type and
+// outputstyle, the same as without any exporter.
+type textExporter struct{}
+
+func (textExporter) Export(w io.Writer, sections []Section) error {
+	for _, s := range sections {
+		if s.Name != "" {
+			if _, err := fmt.Fprintf(w, "***** %s *****\n", s.Name); err != nil {
+				return err
+			}
+		}
+		if err := s.Output(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type jsonSection struct {
+	Name       string              `json:"name"`
+	Operations []map[string]string `json:"operations"`
+}
+
+// jsonExporter writes the summary as a JSON array of sections, every
+// operation is an object keyed by the header.
+type jsonExporter struct{}
+
+func (jsonExporter) Export(w io.Writer, sections []Section) error {
+	data := make([]jsonSection, 0, len(sections))
+	for _, s := range sections {
+		js := jsonSection{Name: s.Name, Operations: make([]map[string]string, 0, len(s.Rows))}
+		for _, row := range s.Rows {
+			op := make(map[string]string, len(s.Header))
+			for i, h := range s.Header {
+				op[h] = row[i]
+			}
+			js.Operations = append(js.Operations, op)
+		}
+		data = append(data, js)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+// csvExporter writes the summary as CSV with the section in the first column.
+// The header is written again before a section with another header, like the
+// top errors.
+type csvExporter struct{}
+
+func (csvExporter) Export(w io.Writer, sections []Section) error {
+	cw := csv.NewWriter(w)
+	var header []string
+	for _, s := range sections {
+		if len(s.Rows) == 0 {
+			continue
+		}
+		if !equalStrings(header, s.Header) {
+			if err := cw.Write(append([]string{"Section"}, s.Header...)); err != nil {
+				return err
+			}
+			header = s.Header
+		}
+		for _, row := range s.Rows {
+			if err := cw.Write(append([]string{s.Name}, row...)); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// markdownExporter writes the summary of every section as a Markdown table.
+type markdownExporter struct{}
+
+func (markdownExporter) Export(w io.Writer, sections []Section) error {
+	for _, s := range sections {
+		if len(s.Rows) == 0 {
+			continue
+		}
+
+		var b strings.Builder
+		if s.Name != "" {
+			fmt.Fprintf(&b, "### %s\n\n", s.Name)
+		}
+		fmt.Fprintf(&b, "|%s|\n", strings.Join(s.Header, "|"))
+		fmt.Fprintf(&b, "|%s\n", strings.Repeat("-|", len(s.Header)))
+		for _, row := range s.Rows {
+			fmt.Fprintf(&b, "|%s|\n", strings.Join(row, "|"))
+		}
+		b.WriteString("\n")
+
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type exporterCreator struct {
+	e Exporter
+}
+
+func (c exporterCreator) Create(p *properties.Properties) (Exporter, error) {
+	return c.e, nil
+}
+
+func init() {
+	RegisterExporterCreator("text", exporterCreator{e: textExporter{}})
+	RegisterExporterCreator("json", exporterCreator{e: jsonExporter{}})
+	RegisterExporterCreator("csv", exporterCreator{e: csvExporter{}})
+	RegisterExporterCreator("markdown", exporterCreator{e: markdownExporter{}})
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExporters(t *testing.T) {
+	sections := []Section{
+		{Name: "A", Header: []string{"Operation", "Count"}, Rows: [][]string{{"READ", "1"}, {"UPDATE", "2"}}},
+		{Name: "B", Header: []string{"Operation", "Count"}, Rows: [][]string{{"READ", "3"}}},
+	}
+	errors := Section{Name: topErrorsSection, Header: errorHeader,
+		Rows: [][]string{{"READ", "TIMEOUT", "4", "i/o timeout"}}}
+
+	cases := []struct {
+		name string
+		out  string
+	}{
+		{"csv", "Section,Operation,Count\nA,READ,1\nA,UPDATE,2\nB,READ,3\n"},
+		{"markdown", "### A\n\n|Operation|Count|\n|-|-|\n|READ|1|\n|UPDATE|2|\n\n" +
+			"### B\n\n|Operation|Count|\n|-|-|\n|READ|3|\n\n"},
+		{"json", `[
+  {
+    "name": "A",
+    "operations": [
+      {
+        "Count": "1",
+        "Operation": "READ"
+      },
+      {
+        "Count": "2",
+        "Operation": "UPDATE"
+      }
+    ]
+  },
+  {
+    "name": "B",
+    "operations": [
+      {
+        "Count": "3",
+        "Operation": "READ"
+      }
+    ]
+  }
+]
+`},
+	}
+	for _, c := range cases {
+		e, err := GetExporterCreator(c.name).Create(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := e.Export(&buf, sections); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != c.out {
+			t.Errorf("%s: want %q, but got %q", c.name, c.out, buf.String())
+		}
+	}
+
+	// the top errors have their own header in CSV
+	var buf bytes.Buffer
+	if err := (csvExporter{}).Export(&buf, append(sections, errors)); err != nil {
+		t.Fatal(err)
+	}
+	want := "Section,Operation,Count\nA,READ,1\nA,UPDATE,2\nB,READ,3\n" +
+		"Section,Operation,Class,Count,Error\nTOP ERRORS,READ,TIMEOUT,4,i/o timeout\n"
+	if buf.String() != want {
+		t.Errorf("want %q, but got %q", want, buf.String())
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	hdrhistogram "github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// hdrLogWriter writes a HdrHistogram interval log, which can be read by the
+// standard tools like HistogramLogAnalyzer. It's shared by all the sections.
+type hdrLogWriter struct {
+	f *os.File
+	w *bufio.Writer
+	// baseTime is the start time in ms since the epoch, the interval
+	// timestamps are relative to it.
+	baseTime int64
+	cfg      *histogramConfig
+	err      error
+}
+
+func newHdrLogWriter(path string, start time.Time, cfg *histogramConfig) (*hdrLogWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &hdrLogWriter{f: f, w: bufio.NewWriter(f), cfg: cfg}
+	// the start time is logged in seconds
+	l.baseTime = start.Unix() * 1000
+
+	lw := hdrhistogram.NewHistogramLogWriter(l.w)
+	if err = lw.OutputLogFormatVersion(); err == nil {
+		err = lw.OutputComment("[Logged with go-ycsb, values are in " + cfg.valueUnit() + "]")
+	}
+	if err == nil {
+		err = lw.OutputStartTime(l.baseTime)
+	}
+	if err == nil {
+		err = lw.OutputLegend()
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// writeInterval writes an interval histogram tagged with the operation.
+func (l *hdrLogWriter) writeInterval(tag string, start time.Time, end time.Time, hist *hdrhistogram.Histogram) {
+	if l.err != nil {
+		return
+	}
+
+	payload, err := hist.Encode(hdrhistogram.V2CompressedEncodingCookieBase)
+	if err != nil {
+		l.err = err
+		return
+	}
+
+	// the interval max is in ms, as the log tools expect
+	startSec := float64(start.UnixNano()/int64(time.Millisecond)-l.baseTime) / 1000
+	_, l.err = fmt.Fprintf(l.w, "Tag=%s,%.3f,%.3f,%.3f,%s\n",
+		tag, startSec, end.Sub(start).Seconds(), l.cfg.millis(hist.Max()), payload)
+}
+
+func (l *hdrLogWriter) flush() error {
+	if l.err != nil {
+		return l.err
+	}
+	return l.w.Flush()
+}
+
+// close flushes and closes the log, nothing is written after it.
+func (l *hdrLogWriter) close() error {
+	if l.f == nil {
+		return nil
+	}
+	err := l.flush()
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	l.f = nil
+	if l.err == nil {
+		l.err = os.ErrClosed
+	}
+	return err
+}
+
+// hdrlog keeps the histograms like the histogram measurement and logs the
+// latencies of every interval to a HdrHistogram interval log.
+type hdrlog struct {
+	*histograms
+
+	log           *hdrLogWriter
+	intervalStart time.Time
+	intervals     map[string]*hdrhistogram.Histogram
+}
+
+// InitHdrLog creates the hdrlog measurement which writes the interval log
+// to the file set by measurement.hdrlog.output_file.
+func InitHdrLog(p *properties.Properties) *hdrlog {
+	path := p.GetString(prop.MeasurementHdrLogOutputFile, prop.MeasurementHdrLogOutputFileDefault)
+	hs := InitHistograms(p)
+	start := time.Now()
+	l, err := newHdrLogWriter(path, start, hs.cfg)
+	if err != nil {
+		panic("failed to create hdrlog output file: " + err.Error())
+	}
+	return newHdrLog(hs, l, start)
+}
+
+func newHdrLog(hs *histograms, l *hdrLogWriter, start time.Time) *hdrlog {
+	return &hdrlog{
+		histograms:    hs,
+		log:           l,
+		intervalStart: start,
+		intervals:     make(map[string]*hdrhistogram.Histogram, 16),
+	}
+}
+
+func (h *hdrlog) Measure(op string, start time.Time, lan time.Duration) {
+	h.histograms.Measure(op, start, lan)
+	h.interval(op).RecordValue(h.cfg.value(lan))
+}
+
+func (h *hdrlog) interval(op string) *hdrhistogram.Histogram {
+	opH, ok := h.intervals[op]
+	if !ok {
+		opH = h.cfg.newHDR()
+		h.intervals[op] = opH
+	}
+	return opH
+}
+
+func (h *hdrlog) merge(r recorder) {
+	s := r.(*histogramShard)
+	for op, opH := range s.ops {
+		if len(opH.values) == 0 {
+			continue
+		}
+		interval := h.interval(op)
+		for _, v := range opH.values {
+			interval.RecordValue(v)
+		}
+	}
+	h.histograms.merge(r)
+}
+
+// logIntervals writes the histograms of the current interval and starts a new one.
+func (h *hdrlog) logIntervals(end time.Time) {
+	for op, opH := range h.intervals {
+		if opH.TotalCount() == 0 {
+			continue
+		}
+		h.log.writeInterval(op, h.intervalStart, end, opH)
+		opH.Reset()
+	}
+	h.intervalStart = end
+}
+
+func (h *hdrlog) Summary() {
+	h.histograms.Summary()
+	h.logIntervals(time.Now())
+	if err := h.log.flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write hdrlog: %v\n", err)
+	}
+}
+
+func (h *hdrlog) Output(w io.Writer) error {
+	h.logIntervals(time.Now())
+	if err := h.log.flush(); err != nil {
+		return err
+	}
+	return h.histograms.Output(w)
+}
+
+func (h *hdrlog) finish(t time.Time) {
+	h.histograms.finish(t)
+	h.logIntervals(t)
+	if err := h.log.flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write hdrlog: %v\n", err)
+	}
+}
+
+func (h *hdrlog) close() error {
+	h.logIntervals(time.Now())
+	return h.log.close()
+}
+
+// nextSection returns the measurement of the next section, which goes on
+// writing the same log.
+func (h *hdrlog) nextSection() ycsb.Measurer {
+	return newHdrLog(InitHistograms(h.p), h.log, time.Now())
+}
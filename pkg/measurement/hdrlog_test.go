@@ -0,0 +1,102 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	hdrhistogram "github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+func TestHdrLogUnit(t *testing.T) {
+	for _, unit := range []string{"ns", "us", "ms"} {
+		path := filepath.Join(t.TempDir(), "go-ycsb.hlog")
+		p := properties.NewProperties()
+		p.Set(prop.MeasurementHdrLogOutputFile, path)
+		p.Set(prop.HistogramTimeUnit, unit)
+		h := InitHdrLog(p)
+		h.Measure("READ", time.Now(), 2*time.Millisecond)
+		h.finish(time.Now())
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		valueUnit := "us"
+		if unit == "ns" {
+			valueUnit = "ns"
+		}
+		if !strings.Contains(string(data), "values are in "+valueUnit+"]") {
+			t.Errorf("%s: want values in %s, but got\n%s", unit, valueUnit, data)
+		}
+		// the interval max is in ms whatever the unit is
+		if !strings.Contains(string(data), ",2.00") {
+			t.Errorf("%s: want the interval max of 2ms, but got\n%s", unit, data)
+		}
+	}
+}
+
+func TestHdrLogReadBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go-ycsb.hlog")
+	p := properties.NewProperties()
+	p.Set(prop.MeasurementType, "hdrlog")
+	p.Set(prop.MeasurementHdrLogOutputFile, path)
+	InitMeasure(p)
+
+	start := time.Now()
+	StartSection("A")
+	Measure("READ", start, time.Millisecond)
+	Measure("UPDATE", start, 2*time.Millisecond)
+	StartSection("B")
+	Measure("READ", start, 3*time.Millisecond)
+	// the last interval is written on close without Output
+	Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r := hdrhistogram.NewHistogramLogReader(f)
+	counts := make(map[string]int64)
+	maxes := make(map[string]int64)
+	for {
+		h, err := r.NextIntervalHistogram()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h == nil {
+			break
+		}
+		if h.StartTimeMs() < start.Unix()*1000 || h.StartTimeMs() > time.Now().UnixNano()/int64(time.Millisecond) {
+			t.Fatalf("%s: bad start time %d", h.Tag(), h.StartTimeMs())
+		}
+		counts[h.Tag()] += h.TotalCount()
+		if h.Max() > maxes[h.Tag()] {
+			maxes[h.Tag()] = h.Max()
+		}
+	}
+	if counts["READ"] != 2 || counts["UPDATE"] != 1 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+	if !hdrhistogram.New(1, 10000, 3).ValuesAreEquivalent(maxes["READ"], 3000) || !hdrhistogram.New(1, 10000, 3).ValuesAreEquivalent(maxes["UPDATE"], 2000) {
+		t.Fatalf("unexpected max latencies in us %v", maxes)
+	}
+}
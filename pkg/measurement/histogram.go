@@ -14,17 +14,149 @@
 package measurement
 
 import (
-	"sort"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	hdrhistogram "github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
 	"github.com/pingcap/go-ycsb/pkg/util"
 )
 
+// infoPercentiles are always in the info of the histograms, for the users
+// like the search command and the report.
+var infoPercentiles = []float64{99, 99.9, 99.99}
+
+// histogramConfig is how the histograms record and print the latencies.
+type histogramConfig struct {
+	// percentiles are printed after the maximum latency.
+	percentiles []float64
+	// unit is the time unit of the printed latencies, ns, us or ms.
+	unit   string
+	digits int
+}
+
+func newHistogramConfig(p *properties.Properties) (*histogramConfig, error) {
+	c := &histogramConfig{
+		unit:   p.GetString(prop.HistogramTimeUnit, prop.HistogramTimeUnitDefault),
+		digits: p.GetInt(prop.HistogramSignificantDigits, prop.HistogramSignificantDigitsDefault),
+	}
+	switch c.unit {
+	case "ns", "us", "ms":
+	default:
+		return nil, fmt.Errorf("unsupported histogram time unit: %s", c.unit)
+	}
+	if c.digits < 1 || c.digits > 5 {
+		return nil, fmt.Errorf("histogram significant digits must be in [1, 5], but got %d", c.digits)
+	}
+
+	for _, s := range splitList(p.GetString(prop.HistogramPercentiles, prop.HistogramPercentilesDefault)) {
+		// max is the 100th percentile
+		name := strings.TrimPrefix(strings.ToLower(s), "p")
+		if name == "max" {
+			name = "100"
+		}
+		v, err := strconv.ParseFloat(name, 64)
+		if err != nil || v <= 0 || v > 100 {
+			return nil, fmt.Errorf("invalid histogram percentile: %s", s)
+		}
+		c.percentiles = append(c.percentiles, v)
+	}
+	return c, nil
+}
+
+// newHDR creates the histogram of the latencies up to a day.
+func (c *histogramConfig) newHDR() *hdrhistogram.Histogram {
+	if c.unit == "ns" {
+		return hdrhistogram.New(1, 24*60*60*1000*1000*1000, c.digits)
+	}
+	return hdrhistogram.New(1, 24*60*60*1000*1000, c.digits)
+}
+
+// value returns the recorded value of the latency, which is in ns if the
+// unit is ns and in us otherwise, so ms keeps the precision of us.
+func (c *histogramConfig) value(lan time.Duration) int64 {
+	if c.unit == "ns" {
+		return lan.Nanoseconds()
+	}
+	return lan.Microseconds()
+}
+
+// valueUnit returns the time unit of the recorded values.
+func (c *histogramConfig) valueUnit() string {
+	if c.unit == "ns" {
+		return "ns"
+	}
+	return "us"
+}
+
+// millis converts the recorded value to ms.
+func (c *histogramConfig) millis(v int64) float64 {
+	if c.unit == "ns" {
+		return float64(v) / 1000000
+	}
+	return float64(v) / 1000
+}
+
+// micros converts the recorded value to us.
+func (c *histogramConfig) micros(v int64) int64 {
+	if c.unit == "ns" {
+		return v / 1000
+	}
+	return v
+}
+
+// format prints the recorded value in the unit.
+func (c *histogramConfig) format(v int64) string {
+	if c.unit == "ms" {
+		return strconv.FormatFloat(float64(v)/1000, 'f', 3, 64)
+	}
+	return util.IntToString(v)
+}
+
+func (c *histogramConfig) header() []string {
+	h := []string{"Operation", "Takes(s)", "Count", "OPS", "MB/s", "Avg Size(B)",
+		"Avg(" + c.unit + ")", "Min(" + c.unit + ")", "Max(" + c.unit + ")"}
+	for _, p := range c.percentiles {
+		h = append(h, percentileName(p)+"("+c.unit+")")
+	}
+	return h
+}
+
+// intervalHeader is appended to header in the periodic summary for the
+// metrics of the last interval.
+func (c *histogramConfig) intervalHeader() []string {
+	return []string{"Intv OPS", "Intv Avg(" + c.unit + ")", "Intv 99th(" + c.unit + ")", "Intv Max(" + c.unit + ")"}
+}
+
+// percentileName returns the name like 99.9th of the percentile.
+func percentileName(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64) + "th"
+}
+
+// PercentileMetric returns the metric name of the percentile in the info,
+// like PER999TH for 99.9.
+func PercentileMetric(p float64) string {
+	return "PER" + strings.ReplaceAll(strconv.FormatFloat(p, 'f', -1, 64), ".", "") + "TH"
+}
+
 type histogram struct {
+	cfg         *histogramConfig
 	boundCounts util.ConcurrentMap
 	startTime   time.Time
+	endTime     time.Time
 	hist        *hdrhistogram.Histogram
+
+	// intervalHist only holds the latencies since the last interval summary,
+	// so a spike isn't averaged out by the whole run.
+	intervalStart time.Time
+	intervalHist  *hdrhistogram.Histogram
+
+	// readBytes and writtenBytes are the payload of the succeeded operations.
+	readBytes    int64
+	writtenBytes int64
 }
 
 // Metric name.
@@ -38,60 +170,115 @@ const (
 	PER99TH   = "PER99TH"
 	PER999TH  = "PER999TH"
 	PER9999TH = "PER9999TH"
+
+	BYTESREAD    = "BYTESREAD"
+	BYTESWRITTEN = "BYTESWRITTEN"
+
+	INTVQPS     = "INTVQPS"
+	INTVAVG     = "INTVAVG"
+	INTVPER99TH = "INTVPER99TH"
+	INTVMAX     = "INTVMAX"
 )
 
-func newHistogram() *histogram {
+func newHistogram(cfg *histogramConfig) *histogram {
 	h := new(histogram)
+	h.cfg = cfg
 	h.startTime = time.Now()
-	h.hist = hdrhistogram.New(1, 24*60*60*1000*1000, 3)
+	h.hist = cfg.newHDR()
+	h.intervalStart = h.startTime
+	h.intervalHist = cfg.newHDR()
 	return h
 }
 
 func (h *histogram) Measure(latency time.Duration) {
-	h.hist.RecordValue(latency.Microseconds())
+	v := h.cfg.value(latency)
+	h.hist.RecordValue(v)
+	h.intervalHist.RecordValue(v)
 }
 
-func (h *histogram) Summary() []string {
-	res := h.getInfo()
+// IntervalSummary returns the summary of the latencies since the last call
+// and starts a new interval.
+func (h *histogram) IntervalSummary() []string {
+	res := h.getIntervalInfo()
+
+	h.intervalHist.Reset()
+	h.intervalStart = time.Now()
 
 	return []string{
-		util.FloatToOneString(res[ELAPSED]),
-		util.IntToString(res[COUNT]),
-		util.FloatToOneString(res[QPS]),
-		util.IntToString(res[AVG]),
-		util.IntToString(res[MIN]),
-		util.IntToString(res[MAX]),
-		util.IntToString(res[PER99TH]),
-		util.IntToString(res[PER999TH]),
-		util.IntToString(res[PER9999TH]),
+		util.FloatToOneString(res[INTVQPS]),
+		h.cfg.format(res[INTVAVG].(int64)),
+		h.cfg.format(res[INTVPER99TH].(int64)),
+		h.cfg.format(res[INTVMAX].(int64)),
 	}
 }
 
-func (h *histogram) getInfo() map[string]interface{} {
-	min := h.hist.Min()
-	max := h.hist.Max()
-	avg := int64(h.hist.Mean())
+func (h *histogram) getIntervalInfo() map[string]interface{} {
+	elapsed := time.Now().Sub(h.intervalStart).Seconds()
+	res := make(map[string]interface{})
+	res[INTVQPS] = float64(h.intervalHist.TotalCount()) / elapsed
+	res[INTVAVG] = int64(h.intervalHist.Mean())
+	res[INTVPER99TH] = h.intervalHist.ValueAtPercentile(99)
+	res[INTVMAX] = h.intervalHist.Max()
+	return res
+}
+
+func (h *histogram) elapsed() float64 {
+	endTime := h.endTime
+	if endTime.IsZero() {
+		endTime = time.Now()
+	}
+	return endTime.Sub(h.startTime).Seconds()
+}
+
+func (h *histogram) Summary() []string {
+	elapsed := h.elapsed()
 	count := h.hist.TotalCount()
+	res := []string{
+		util.FloatToOneString(elapsed),
+		util.IntToString(count),
+		util.FloatToOneString(float64(count) / elapsed),
+		util.FloatToOneString(float64(h.readBytes+h.writtenBytes) / elapsed / (1 << 20)),
+		util.IntToString(h.avgPayload()),
+		h.cfg.format(int64(h.hist.Mean())),
+		h.cfg.format(h.hist.Min()),
+		h.cfg.format(h.hist.Max()),
+	}
+	for _, p := range h.cfg.percentiles {
+		res = append(res, h.cfg.format(h.hist.ValueAtPercentile(p)))
+	}
+	return res
+}
 
-	bounds := h.boundCounts.Keys()
-	sort.Ints(bounds)
+// avgPayload returns the average bytes read and written by an operation.
+func (h *histogram) avgPayload() int64 {
+	count := h.hist.TotalCount()
+	if count == 0 {
+		return 0
+	}
+	return (h.readBytes + h.writtenBytes) / count
+}
 
-	per99 := h.hist.ValueAtPercentile(99)
-	per999 := h.hist.ValueAtPercentile(99.9)
-	per9999 := h.hist.ValueAtPercentile(99.99)
+// getInfo returns the metrics of the histogram, the latencies are in us
+// whatever the unit is.
+func (h *histogram) getInfo() map[string]interface{} {
+	elapsed := h.elapsed()
+	count := h.hist.TotalCount()
+	us := h.cfg.micros
 
-	elapsed := time.Now().Sub(h.startTime).Seconds()
-	qps := float64(count) / elapsed
 	res := make(map[string]interface{})
 	res[ELAPSED] = elapsed
 	res[COUNT] = count
-	res[QPS] = qps
-	res[AVG] = avg
-	res[MIN] = min
-	res[MAX] = max
-	res[PER99TH] = per99
-	res[PER999TH] = per999
-	res[PER9999TH] = per9999
-
+	res[QPS] = float64(count) / elapsed
+	res[AVG] = us(int64(h.hist.Mean()))
+	res[MIN] = us(h.hist.Min())
+	res[MAX] = us(h.hist.Max())
+	res[BYTESREAD] = h.readBytes
+	res[BYTESWRITTEN] = h.writtenBytes
+	for _, p := range infoPercentiles {
+		res[PercentileMetric(p)] = us(h.hist.ValueAtPercentile(p))
+	}
+	for _, p := range h.cfg.percentiles {
+		res[PercentileMetric(p)] = us(h.hist.ValueAtPercentile(p))
+	}
 	return res
 }
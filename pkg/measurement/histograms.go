@@ -12,7 +12,8 @@ import (
 )
 
 type histograms struct {
-	p *properties.Properties
+	p   *properties.Properties
+	cfg *histogramConfig
 
 	histograms map[string]*histogram
 }
@@ -20,27 +21,148 @@ type histograms struct {
 func (h *histograms) Measure(op string, start time.Time, lan time.Duration) {
 	opM, ok := h.histograms[op]
 	if !ok {
-		opM = newHistogram()
+		opM = newHistogram(h.cfg)
 		h.histograms[op] = opM
 	}
 
 	opM.Measure(lan)
 }
 
-func (h *histograms) summary() map[string][]string {
+func (h *histograms) measurePayload(op string, read int64, written int64) {
+	opM, ok := h.histograms[op]
+	if !ok {
+		opM = newHistogram(h.cfg)
+		h.histograms[op] = opM
+	}
+	opM.readBytes += read
+	opM.writtenBytes += written
+}
+
+// maxShardValues is the most latencies a thread buffers before they're
+// merged, so a shard takes tens of KB however precise the histograms are.
+const maxShardValues = 4096
+
+// shardHistogram holds the latencies of an operation recorded by a thread
+// since the last merge.
+type shardHistogram struct {
+	// start is when the first latency since the last merge is recorded
+	start  time.Time
+	values []int64
+
+	readBytes    int64
+	writtenBytes int64
+}
+
+// histogramShard is the recorder of a thread for histograms.
+type histogramShard struct {
+	cfg *histogramConfig
+	ops map[string]*shardHistogram
+	// buffered is the number of the latencies of all the operations.
+	buffered int
+}
+
+func (s *histogramShard) op(op string) *shardHistogram {
+	opH, ok := s.ops[op]
+	if !ok {
+		opH = new(shardHistogram)
+		s.ops[op] = opH
+	}
+	return opH
+}
+
+func (s *histogramShard) Measure(op string, start time.Time, lan time.Duration) {
+	opH := s.op(op)
+	if opH.start.IsZero() {
+		opH.start = time.Now()
+	}
+	opH.values = append(opH.values, s.cfg.value(lan))
+	s.buffered++
+}
+
+func (s *histogramShard) measurePayload(op string, read int64, written int64) {
+	opH := s.op(op)
+	opH.readBytes += read
+	opH.writtenBytes += written
+}
+
+func (s *histogramShard) full() bool {
+	return s.buffered >= maxShardValues
+}
+
+func (h *histograms) newShard() recorder {
+	return &histogramShard{cfg: h.cfg, ops: make(map[string]*shardHistogram, 16)}
+}
+
+func (h *histograms) merge(r recorder) {
+	s := r.(*histogramShard)
+	for op, opH := range s.ops {
+		if opH.start.IsZero() && opH.readBytes == 0 && opH.writtenBytes == 0 {
+			continue
+		}
+
+		opM, ok := h.histograms[op]
+		if !ok {
+			opM = newHistogram(h.cfg)
+			if !opH.start.IsZero() {
+				opM.startTime = opH.start
+				opM.intervalStart = opH.start
+			}
+			h.histograms[op] = opM
+		}
+		for _, v := range opH.values {
+			opM.hist.RecordValue(v)
+			opM.intervalHist.RecordValue(v)
+		}
+		opM.readBytes += opH.readBytes
+		opM.writtenBytes += opH.writtenBytes
+
+		opH.start = time.Time{}
+		opH.values = opH.values[:0]
+		opH.readBytes, opH.writtenBytes = 0, 0
+	}
+	s.buffered = 0
+}
+
+func (h *histograms) finish(t time.Time) {
+	for _, opM := range h.histograms {
+		opM.endTime = t
+	}
+}
+
+func (h *histograms) info() map[string]map[string]interface{} {
+	infos := make(map[string]map[string]interface{}, len(h.histograms))
+	for op, opM := range h.histograms {
+		infos[op] = opM.getInfo()
+	}
+	return infos
+}
+
+func (h *histograms) summary(withInterval bool) map[string][]string {
 	summaries := make(map[string][]string, len(h.histograms))
 	for op, opM := range h.histograms {
 		summaries[op] = opM.Summary()
+		if withInterval {
+			summaries[op] = append(summaries[op], opM.IntervalSummary()...)
+		}
 	}
 	return summaries
 }
 
+// Summary prints the cumulative metrics together with the ones of the last interval.
 func (h *histograms) Summary() {
-	h.Output(os.Stdout)
+	h.output(os.Stdout, true)
 }
 
 func (h *histograms) Output(w io.Writer) error {
-	summaries := h.summary()
+	return h.output(w, false)
+}
+
+func (h *histograms) summaryRows() ([]string, [][]string) {
+	return h.rows(false)
+}
+
+func (h *histograms) rows(withInterval bool) ([]string, [][]string) {
+	summaries := h.summary(withInterval)
 	keys := make([]string, 0, len(summaries))
 	for k := range summaries {
 		keys = append(keys, k)
@@ -54,7 +176,21 @@ func (h *histograms) Output(w io.Writer) error {
 		lines = append(lines, line)
 	}
 
-	outputStyle := h.p.GetString(prop.OutputStyle, util.OutputStylePlain)
+	outputHeader := h.cfg.header()
+	if withInterval {
+		outputHeader = append(outputHeader, h.cfg.intervalHeader()...)
+	}
+	return outputHeader, lines
+}
+
+func (h *histograms) output(w io.Writer, withInterval bool) error {
+	outputHeader, lines := h.rows(withInterval)
+
+	renderRows(w, h.p.GetString(prop.OutputStyle, util.OutputStylePlain), outputHeader, lines)
+	return nil
+}
+
+func renderRows(w io.Writer, outputStyle string, header []string, lines [][]string) {
 	switch outputStyle {
 	case util.OutputStylePlain:
 		util.RenderString(w, "%-6s - %s\n", header, lines)
@@ -65,12 +201,16 @@ func (h *histograms) Output(w io.Writer) error {
 	default:
 		panic("unsupported outputstyle: " + outputStyle)
 	}
-	return nil
 }
 
 func InitHistograms(p *properties.Properties) *histograms {
+	cfg, err := newHistogramConfig(p)
+	if err != nil {
+		util.Fatalf("%v", err)
+	}
 	return &histograms{
 		p:          p,
+		cfg:        cfg,
 		histograms: make(map[string]*histogram, 16),
 	}
 }
@@ -15,24 +15,129 @@ package measurement
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"os"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/magiconair/properties"
 	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 )
 
-var header = []string{"Operation", "Takes(s)", "Count", "OPS", "Avg(us)", "Min(us)", "Max(us)", "99th(us)", "99.9th(us)", "99.99th(us)"}
+// section is a named part of the run measured separately, like a phase.
+type section struct {
+	name     string
+	measurer ycsb.Measurer
+	// start and end are when the section is measured, end is zero for the
+	// current section.
+	start time.Time
+	end   time.Time
+}
+
+// finisher is implemented by the measurers which need to know when the
+// measurement ends, e.g. to compute the throughput of a finished section.
+type finisher interface {
+	finish(t time.Time)
+}
+
+// infoMeasurer is implemented by the measurers which keep the metrics of
+// every operation, see Info.
+type infoMeasurer interface {
+	info() map[string]map[string]interface{}
+}
+
+// summarizer is implemented by the measurers which keep a summary of every
+// operation, which is passed to the exporters.
+type summarizer interface {
+	summaryRows() (header []string, rows [][]string)
+}
+
+// streamer is implemented by the measurers which write their output to a
+// file while measuring, instead of on output.
+type streamer interface {
+	// closeStream writes what's left and closes the file.
+	closeStream() error
+}
+
+// closer is implemented by the measurers which keep a file open until the
+// program exits, like the hdrlog file.
+type closer interface {
+	close() error
+}
+
+// sectioner is implemented by the measurers which share their state with
+// the measurer of the next section, like an output file.
+type sectioner interface {
+	nextSection() ycsb.Measurer
+}
+
+// recorder records the operations measured by one thread.
+type recorder interface {
+	Measure(op string, start time.Time, lan time.Duration)
+}
+
+// sharder is implemented by the measurers which can be split per thread, so
+// the threads don't contend on the global lock. The shards are merged into
+// the measurer before it reports.
+type sharder interface {
+	newShard() recorder
+	// merge moves the measurements of the shard into the measurer and leaves
+	// the shard empty.
+	merge(shard recorder)
+}
+
+// bufferedRecorder is implemented by the shards which buffer a bounded
+// number of measurements, they're merged as soon as they're full.
+type bufferedRecorder interface {
+	full() bool
+}
+
+// payloadRecorder is implemented by the measurers and the recorders which
+// count the bytes read and written by the operations.
+type payloadRecorder interface {
+	measurePayload(op string, read int64, written int64)
+}
+
+// shard is the recorder of one thread, its lock is only contended when the
+// shards are merged.
+type shard struct {
+	sync.Mutex
+	r recorder
+}
 
 type measurement struct {
-	sync.RWMutex
+	sync.Mutex
 
 	p *properties.Properties
 
 	measurer ycsb.Measurer
+	// shards are indexed by the thread ID, they are empty if the measurer
+	// isn't a sharder.
+	shards []*shard
+	// sectionName is the name of the current section, empty if the run
+	// doesn't have sections.
+	sectionName string
+	// sectionStart is when the current section starts, or when warm-up
+	// finishes if later.
+	sectionStart time.Time
+	// sections are the finished sections.
+	sections []section
+
+	exporters []namedExporter
+	errors    *errorTracker
+}
+
+// namedExporter is an exporter with the file it writes to, an empty file
+// means stdout.
+type namedExporter struct {
+	name     string
+	exporter Exporter
+	file     string
 }
 
 func (m *measurement) measure(op string, start time.Time, lan time.Duration) {
@@ -41,9 +146,97 @@ func (m *measurement) measure(op string, start time.Time, lan time.Duration) {
 	m.Unlock()
 }
 
+func (m *measurement) measureThread(threadID int, op string, start time.Time, lan time.Duration) {
+	if threadID < 0 || threadID >= len(m.shards) {
+		m.measure(op, start, lan)
+		return
+	}
+
+	s := m.shards[threadID]
+	s.Lock()
+	s.r.Measure(op, start, lan)
+	b, ok := s.r.(bufferedRecorder)
+	full := ok && b.full()
+	s.Unlock()
+
+	if full {
+		m.Lock()
+		m.mergeShard(s)
+		m.Unlock()
+	}
+}
+
+func (m *measurement) measurePayload(threadID int, op string, read int64, written int64) {
+	if threadID < 0 || threadID >= len(m.shards) {
+		m.Lock()
+		if r, ok := m.measurer.(payloadRecorder); ok {
+			r.measurePayload(op, read, written)
+		}
+		m.Unlock()
+		return
+	}
+
+	s := m.shards[threadID]
+	s.Lock()
+	if r, ok := s.r.(payloadRecorder); ok {
+		r.measurePayload(op, read, written)
+	}
+	s.Unlock()
+}
+
+func (m *measurement) initShards(threadCount int) {
+	sh, ok := m.measurer.(sharder)
+	if !ok {
+		return
+	}
+
+	m.shards = make([]*shard, threadCount)
+	for i := range m.shards {
+		m.shards[i] = &shard{r: sh.newShard()}
+	}
+}
+
+// mergeShards merges the measurements of all the threads into the measurer,
+// it must be called with the lock held.
+func (m *measurement) mergeShards() {
+	for _, s := range m.shards {
+		m.mergeShard(s)
+	}
+}
+
+// mergeShard merges the measurements of a thread into the measurer, it must
+// be called with the lock held.
+func (m *measurement) mergeShard(s *shard) {
+	if sh, ok := m.measurer.(sharder); ok {
+		s.Lock()
+		sh.merge(s.r)
+		s.Unlock()
+	}
+}
+
 func (m *measurement) output() {
-	m.RLock()
-	defer m.RUnlock()
+	// finishing the measurement needs the write lock
+	m.Lock()
+	defer m.Unlock()
+
+	m.mergeShards()
+	if f, ok := m.measurer.(finisher); ok {
+		f.finish(time.Now())
+	}
+
+	if s, ok := m.measurer.(streamer); ok {
+		if err := s.closeStream(); err != nil {
+			panic("failed to write output: " + err.Error())
+		}
+		if m.p.GetString(prop.MeasurementRawOutputFile, "") != "" {
+			return
+		}
+	}
+
+	if len(m.exporters) > 0 {
+		m.export()
+		return
+	}
 
 	outFile := m.p.GetString(prop.MeasurementRawOutputFile, "")
 	var w *bufio.Writer
@@ -58,7 +251,7 @@ func (m *measurement) output() {
 		w = bufio.NewWriter(f)
 	}
 
-	err := globalMeasure.measurer.Output(w)
+	err := m.outputSections(w)
 	if err != nil {
 		panic("failed to write output: " + err.Error())
 	}
@@ -69,33 +262,263 @@ func (m *measurement) output() {
 	}
 }
 
+func (m *measurement) export() {
+	sections := m.exportSections(time.Now())
+	if t := m.errorTable(); len(t.rows) > 0 {
+		sections = append(sections, newSection(section{name: topErrorsSection, measurer: t}))
+	}
+
+	for _, e := range m.exporters {
+		if err := exportTo(e, sections); err != nil {
+			panic(fmt.Sprintf("failed to export with %s: %v", e.name, err))
+		}
+	}
+}
+
+func exportTo(e namedExporter, sections []Section) error {
+	var w *bufio.Writer
+	if e.file == "" {
+		w = bufio.NewWriter(os.Stdout)
+	} else {
+		f, err := os.Create(e.file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = bufio.NewWriter(f)
+	}
+
+	if err := e.exporter.Export(w, sections); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// newExporters creates the exporters listed in the exporter property, each
+// of them writes to the file at the same position in the exportfile property.
+func newExporters(p *properties.Properties) []namedExporter {
+	names := splitList(p.GetString(prop.Exporter, ""))
+	files := splitList(p.GetString(prop.ExportFile, ""))
+
+	exporters := make([]namedExporter, 0, len(names))
+	for i, name := range names {
+		if name == "" {
+			continue
+		}
+		creator := GetExporterCreator(name)
+		if creator == nil {
+			panic("unsupported exporter: " + name)
+		}
+		exporter, err := creator.Create(p)
+		if err != nil {
+			panic(fmt.Sprintf("failed to create exporter %s: %v", name, err))
+		}
+
+		e := namedExporter{name: name, exporter: exporter}
+		if i < len(files) {
+			e.file = files[i]
+		}
+		exporters = append(exporters, e)
+	}
+	return exporters
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	items := strings.Split(s, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
+
+// exportSections returns the finished sections and the current one, which
+// ends at now. It must be called with the lock held.
+func (m *measurement) exportSections(now time.Time) []Section {
+	sections := make([]Section, 0, len(m.sections)+2)
+	for _, s := range m.sections {
+		sections = append(sections, newSection(s))
+	}
+	cur := section{name: m.sectionName, measurer: m.measurer, start: m.sectionStart, end: now}
+	return append(sections, newSection(cur))
+}
+
+func (m *measurement) errorTable() *errorTable {
+	return m.errors.table(m.p.GetString(prop.OutputStyle, util.OutputStylePlain))
+}
+
+func (m *measurement) outputSections(w io.Writer) error {
+	var sections []section
+	if m.sectionName == "" {
+		if err := m.measurer.Output(w); err != nil {
+			return err
+		}
+	} else {
+		sections = append(m.sections, section{name: m.sectionName, measurer: m.measurer})
+	}
+	if t := m.errorTable(); len(t.rows) > 0 {
+		sections = append(sections, section{name: topErrorsSection, measurer: t})
+	}
+
+	for _, s := range sections {
+		if _, err := fmt.Fprintf(w, "***** %s *****\n", s.name); err != nil {
+			return err
+		}
+		if err := s.measurer.Output(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *measurement) startSection(name string) {
+	m.Lock()
+	defer m.Unlock()
+
+	// the shards are merged into the old section before they go on recording
+	// for the new one
+	m.mergeShards()
+	if m.sectionName != "" {
+		if f, ok := m.measurer.(finisher); ok {
+			f.finish(time.Now())
+		}
+		m.sections = append(m.sections, section{
+			name:     m.sectionName,
+			measurer: m.measurer,
+			start:    m.sectionStart,
+			end:      time.Now(),
+		})
+	}
+	m.sectionName = name
+	m.sectionStart = time.Now()
+	if s, ok := m.measurer.(sectioner); ok {
+		m.measurer = s.nextSection()
+	} else {
+		m.measurer = newMeasurer(m.p)
+	}
+}
+
+func (m *measurement) close() {
+	m.Lock()
+	defer m.Unlock()
+
+	m.mergeShards()
+	if s, ok := m.measurer.(streamer); ok {
+		if err := s.closeStream(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write output: %v\n", err)
+		}
+	}
+	if c, ok := m.measurer.(closer); ok {
+		if err := c.close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close measurement: %v\n", err)
+		}
+	}
+}
+
+func (m *measurement) warmUpFinished(t time.Time) {
+	m.Lock()
+	m.sectionStart = t
+	m.Unlock()
+}
+
+func (m *measurement) exportAll() []Section {
+	m.Lock()
+	defer m.Unlock()
+
+	m.mergeShards()
+	return m.exportSections(time.Now())
+}
+
+func (m *measurement) info() map[string]map[string]interface{} {
+	m.Lock()
+	defer m.Unlock()
+
+	m.mergeShards()
+	if i, ok := m.measurer.(infoMeasurer); ok {
+		return i.info()
+	}
+	return nil
+}
+
 func (m *measurement) summary() {
-	m.RLock()
-	globalMeasure.measurer.Summary()
-	m.RUnlock()
+	m.Lock()
+	m.mergeShards()
+	m.measurer.Summary()
+	m.Unlock()
 }
 
-// InitMeasure initializes the global measurement.
-func InitMeasure(p *properties.Properties) {
-	globalMeasure = new(measurement)
-	globalMeasure.p = p
+func newMeasurer(p *properties.Properties) ycsb.Measurer {
 	measurementType := p.GetString(prop.MeasurementType, prop.MeasurementTypeDefault)
 	switch measurementType {
 	case "histogram":
-		globalMeasure.measurer = InitHistograms(p)
+		return InitHistograms(p)
 	case "raw", "csv":
-		globalMeasure.measurer = InitCSV()
+		return InitCSV(p)
+	case "hdrlog":
+		return InitHdrLog(p)
 	default:
 		panic("unsupported measurement type: " + measurementType)
 	}
-	EnableWarmUp(p.GetInt64(prop.WarmUpTime, 0) > 0)
 }
 
-// Output prints the complete measurements.
+// InitMeasure initializes the global measurement.
+func InitMeasure(p *properties.Properties) {
+	globalMeasure = new(measurement)
+	globalMeasure.p = p
+	globalMeasure.measurer = newMeasurer(p)
+	globalMeasure.sectionStart = time.Now()
+	globalMeasure.exporters = newExporters(p)
+	globalMeasure.errors = newErrorTracker(p)
+	globalMeasure.initShards(p.GetInt(prop.ThreadCount, 1))
+	EnableWarmUp(p.GetInt64(prop.WarmUpTime, 0) > 0 || p.GetBool(prop.WarmUpAdaptive, prop.WarmUpAdaptiveDefault))
+}
+
+// Output prints the complete measurements, or runs the exporters if any.
 func Output() {
 	globalMeasure.output()
 }
 
+// Close writes what's left to the measurement files, like the hdrlog file
+// and the raw samples, and closes them, so they're complete even if the run
+// ends without Output. Nothing is measured after it.
+func Close() {
+	if globalMeasure != nil {
+		globalMeasure.close()
+	}
+}
+
+// StartSection finishes the current measurement section and starts a new one
+// with the given name, every section is printed separately in the output.
+func StartSection(name string) {
+	globalMeasure.startSection(name)
+}
+
+// SetThreadCount sets the number of the threads measuring separately, it
+// must be called while no thread is measuring.
+func SetThreadCount(n int) {
+	globalMeasure.Lock()
+	defer globalMeasure.Unlock()
+
+	globalMeasure.mergeShards()
+	globalMeasure.initShards(n)
+}
+
+// Sections returns the sections measured so far with the current one last,
+// which ends now. A run without sections has one section without name.
+func Sections() []Section {
+	return globalMeasure.exportAll()
+}
+
+// Info returns the metrics of the operations measured in the current section,
+// keyed by the operation and then by the metric name like COUNT or PER99TH,
+// see PercentileMetric for the configured percentiles. The latencies are in us.
+// It returns nil if the measurement type doesn't keep the metrics.
+func Info() map[string]map[string]interface{} {
+	return globalMeasure.info()
+}
+
 // Summary prints the measurement summary.
 func Summary() {
 	globalMeasure.summary()
@@ -105,8 +528,9 @@ func Summary() {
 func EnableWarmUp(b bool) {
 	if b {
 		atomic.StoreInt32(&warmUp, 1)
-	} else {
-		atomic.StoreInt32(&warmUp, 0)
+	} else if atomic.SwapInt32(&warmUp, 0) == 1 && globalMeasure != nil {
+		// the current section is measured from now on
+		globalMeasure.warmUpFinished(time.Now())
 	}
 }
 
@@ -115,12 +539,69 @@ func IsWarmUpFinished() bool {
 	return atomic.LoadInt32(&warmUp) == 0
 }
 
+// MeasureError measures the failed operation of the thread like MeasureThread.
+// The class is the kind of the error like TIMEOUT, the operation is measured
+// separately for the class if the class is tracked. The message is counted
+// for the top errors in the output.
+func MeasureError(threadID int, op string, class string, msg string, start time.Time, lan time.Duration) {
+	name := globalMeasure.errors.opName(op, class)
+	for _, o := range observers {
+		if eo, ok := o.(ErrorObserver); ok {
+			eo.ObserveError(op, class, start, lan)
+		} else {
+			o.Observe(name, start, lan)
+		}
+	}
+	if IsWarmUpFinished() {
+		globalMeasure.measureThread(threadID, name, start, lan)
+		globalMeasure.errors.record(op, class, msg)
+	}
+}
+
+// Observer is notified of every operation as it's done, including the ones
+// during warm-up, e.g. to export live metrics. It must be safe for concurrent use.
+type Observer interface {
+	Observe(op string, start time.Time, lan time.Duration)
+}
+
+// ErrorObserver is an Observer notified of the failed operations with their
+// error class, instead of the name they're measured under like READ_ERROR.
+type ErrorObserver interface {
+	Observer
+	ObserveError(op string, class string, start time.Time, lan time.Duration)
+}
+
+// AddObserver adds an observer of the operations, it must be called before
+// the workload runs.
+func AddObserver(o Observer) {
+	observers = append(observers, o)
+}
+
 // Measure measures the operation.
 func Measure(op string, start time.Time, lan time.Duration) {
+	MeasureThread(-1, op, start, lan)
+}
+
+// MeasureThread measures the operation done by the thread, the threads
+// measure separately without contending on a lock. A negative threadID
+// means the operation isn't done by a client thread.
+func MeasureThread(threadID int, op string, start time.Time, lan time.Duration) {
+	for _, o := range observers {
+		o.Observe(op, start, lan)
+	}
+	if IsWarmUpFinished() {
+		globalMeasure.measureThread(threadID, op, start, lan)
+	}
+}
+
+// MeasurePayload counts the bytes read and written by the operation done by
+// the thread like MeasureThread, only the histograms count them.
+func MeasurePayload(threadID int, op string, read int64, written int64) {
 	if IsWarmUpFinished() {
-		globalMeasure.measure(op, start, lan)
+		globalMeasure.measurePayload(threadID, op, read, written)
 	}
 }
 
 var globalMeasure *measurement
+var observers []Observer
 var warmUp int32 // use as bool, 1 means in warmup progress, 0 means warmup finished.
@@ -0,0 +1,190 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+func initBenchMeasure() {
+	p := properties.NewProperties()
+	p.Set(prop.ThreadCount, strconv.Itoa(runtime.GOMAXPROCS(0)))
+	InitMeasure(p)
+}
+
+func TestMeasureThread(t *testing.T) {
+	p := properties.NewProperties()
+	p.Set(prop.ThreadCount, "4")
+	InitMeasure(p)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 100; j++ {
+			MeasureThread(i, "READ", start, time.Duration(i*100+j+1)*time.Microsecond)
+		}
+	}
+	// the operations without a thread go to the measurer directly
+	Measure("READ", start, 401*time.Microsecond)
+
+	info := Info()["READ"]
+	if info[COUNT] != int64(401) || info[MIN] != int64(1) || info[MAX] != int64(401) {
+		t.Fatalf("unexpected merged info %v", info)
+	}
+
+	// the shards are empty after merged
+	MeasureThread(0, "READ", start, time.Microsecond)
+	if count := Info()["READ"][COUNT]; count != int64(402) {
+		t.Fatalf("want 402 operations, but got %v", count)
+	}
+
+	// a full shard is merged without waiting for the summary
+	for i := 0; i < maxShardValues; i++ {
+		MeasureThread(1, "UPDATE", start, time.Millisecond)
+	}
+	if opM := globalMeasure.measurer.(*histograms).histograms["UPDATE"]; opM == nil || opM.hist.TotalCount() != maxShardValues {
+		t.Fatalf("the full shard isn't merged")
+	}
+}
+
+func TestHistogramConfig(t *testing.T) {
+	p := properties.NewProperties()
+	p.Set(prop.HistogramPercentiles, "p50,p99.9")
+	p.Set(prop.HistogramTimeUnit, "ms")
+	h := InitHistograms(p)
+	h.Measure("READ", time.Now(), 1500*time.Microsecond)
+	h.measurePayload("READ", 1000, 24)
+
+	header, rows := h.summaryRows()
+	want := []string{"Operation", "Takes(s)", "Count", "OPS", "MB/s", "Avg Size(B)", "Avg(ms)", "Min(ms)", "Max(ms)", "50th(ms)", "99.9th(ms)"}
+	if !reflect.DeepEqual(header, want) {
+		t.Fatalf("want header %v, but got %v", want, header)
+	}
+	if row := rows[0]; len(row) != len(want) || row[5] != "1024" || row[6] != "1.500" || row[9] != "1.500" {
+		t.Fatalf("unexpected row %v", row)
+	}
+
+	// the info is in us with the default percentiles
+	info := h.info()["READ"]
+	if info[PercentileMetric(50)] != int64(1500) || info[PER99TH] != int64(1500) || info[BYTESREAD] != int64(1000) {
+		t.Fatalf("unexpected info %v", info)
+	}
+}
+
+func TestHistogramConfigPercentiles(t *testing.T) {
+	tests := []struct {
+		percentiles string
+		want        []float64
+	}{
+		{"p50,99.9", []float64{50, 99.9}},
+		{"p99,max", []float64{99, 100}},
+		{"MAX", []float64{100}},
+		{"p0", nil},
+		{"p101", nil},
+		{"maximum", nil},
+	}
+	for _, tt := range tests {
+		p := properties.NewProperties()
+		p.Set(prop.HistogramPercentiles, tt.percentiles)
+		c, err := newHistogramConfig(p)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("%s: want an error", tt.percentiles)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.percentiles, err)
+		} else if !reflect.DeepEqual(c.percentiles, tt.want) {
+			t.Errorf("%s: want %v, but got %v", tt.percentiles, tt.want, c.percentiles)
+		}
+	}
+}
+
+func TestIntervalSummary(t *testing.T) {
+	h := InitHistograms(properties.NewProperties())
+	shard := h.newShard()
+	start := time.Now()
+	shard.Measure("READ", start, 2*time.Millisecond)
+	h.merge(shard)
+
+	header, rows := h.rows(true)
+	n := len(h.cfg.header())
+	want := []string{"Intv OPS", "Intv Avg(us)", "Intv 99th(us)", "Intv Max(us)"}
+	if !reflect.DeepEqual(header[n:], want) {
+		t.Fatalf("want interval header %v, but got %v", want, header[n:])
+	}
+	if row := rows[0]; len(row) != len(header) || row[n+1] != "2000" || row[n+3] != "2000" {
+		t.Fatalf("unexpected row %v", row)
+	}
+
+	// the interval only has the latencies since the last summary
+	shard.Measure("READ", start, time.Millisecond)
+	h.merge(shard)
+	_, rows = h.rows(true)
+	if row := rows[0]; row[2] != "2" || row[8] != "2000" || row[n+1] != "1000" || row[n+3] != "1000" {
+		t.Fatalf("unexpected row %v", row)
+	}
+
+	_, rows = h.rows(true)
+	if row := rows[0]; row[2] != "2" || row[n] != "0.0" || row[n+3] != "0" {
+		t.Fatalf("want an empty interval, but got %v", rows[0])
+	}
+
+	// the final output has no interval columns
+	header, rows = h.summaryRows()
+	if len(header) != n || len(rows[0]) != n {
+		t.Fatalf("unexpected final summary %v %v", header, rows)
+	}
+}
+
+// BenchmarkMeasure measures concurrently through the global lock.
+func BenchmarkMeasure(b *testing.B) {
+	initBenchMeasure()
+	start := time.Now()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			Measure("READ", start, time.Millisecond)
+		}
+	})
+}
+
+// BenchmarkMeasureThread measures concurrently with a shard per thread.
+func BenchmarkMeasureThread(b *testing.B) {
+	initBenchMeasure()
+	start := time.Now()
+	var nextID int32
+	b.RunParallel(func(pb *testing.PB) {
+		threadID := int(atomic.AddInt32(&nextID, 1) - 1)
+		for pb.Next() {
+			MeasureThread(threadID, "READ", start, time.Millisecond)
+		}
+	})
+
+	// the memory of a thread doesn't depend on the histogram precision
+	var size int
+	for _, s := range globalMeasure.shards {
+		for _, opH := range s.r.(*histogramShard).ops {
+			size += cap(opH.values) * 8
+		}
+	}
+	b.ReportMetric(float64(size)/float64(len(globalMeasure.shards)), "B/shard")
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics exports the live metrics of the benchmark to Prometheus.
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/client"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ycsb"
+
+// Path is where the metrics are served.
+const Path = "/metrics"
+
+type observer struct {
+	ops       *prometheus.CounterVec
+	errs      *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+}
+
+// intendedPrefix is the prefix of the operations measured again from their
+// intended start in open-loop mode, they're done once so aren't counted.
+const intendedPrefix = "INTENDED_"
+
+// txnSteps are the steps of a transaction, the transaction is counted as a
+// whole as TXN by the workload.
+var txnSteps = map[string]bool{
+	"BEGIN":     true,
+	"TXN_READ":  true,
+	"TXN_WRITE": true,
+	"COMMIT":    true,
+	"ABORT":     true,
+}
+
+// Observe implements measurement.Observer for the succeeded operations.
+func (o *observer) Observe(op string, _ time.Time, lan time.Duration) {
+	if strings.HasPrefix(op, intendedPrefix) || txnSteps[op] {
+		return
+	}
+	o.ops.WithLabelValues(op).Inc()
+	o.durations.WithLabelValues(op, "ok").Observe(lan.Seconds())
+}
+
+// ObserveError implements measurement.ErrorObserver, the failed operations
+// are counted by the operation and the error class. A failed step fails the
+// transaction, so it's counted as a failed TXN.
+func (o *observer) ObserveError(op string, class string, _ time.Time, lan time.Duration) {
+	if strings.HasPrefix(op, intendedPrefix) {
+		return
+	}
+	if txnSteps[op] {
+		op = "TXN"
+	}
+	o.errs.WithLabelValues(op, class).Inc()
+	o.ops.WithLabelValues(op).Inc()
+	o.durations.WithLabelValues(op, "error").Observe(lan.Seconds())
+}
+
+var initOnce sync.Once
+
+// Init registers the metrics of the operations, the running threads and
+// the current target, and serves them on Path of the default HTTP server.
+// All the metrics are labeled with the label property, the db and the command,
+// so concurrent runs can be told apart. Only the first call takes effect.
+func Init(p *properties.Properties, dbName string) {
+	initOnce.Do(func() {
+		labels := prometheus.Labels{
+			"label":   p.GetString(prop.Label, ""),
+			"db":      dbName,
+			"command": p.GetString(prop.Command, ""),
+		}
+		o, collectors := newCollectors(labels)
+		prometheus.MustRegister(collectors...)
+		measurement.AddObserver(o)
+		http.Handle(Path, promhttp.Handler())
+	})
+}
+
+// newCollectors returns the observer of the operations and all the metrics
+// to register.
+func newCollectors(labels prometheus.Labels) (*observer, []prometheus.Collector) {
+	o := &observer{
+		ops: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "operations_total",
+			Help:        "Total number of operations, a batch counts as one operation.",
+			ConstLabels: labels,
+		}, []string{"op"}),
+		errs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "operation_errors_total",
+			Help:        "Total number of failed operations.",
+			ConstLabels: labels,
+		}, []string{"op", "class"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Name:        "operation_duration_seconds",
+			Help:        "Latency of the operations.",
+			ConstLabels: labels,
+			// 50us to about 6.5s
+			Buckets: prometheus.ExponentialBuckets(0.00005, 2, 18),
+		}, []string{"op", "status"}),
+	}
+
+	threads := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "threads",
+		Help:        "Number of client threads running now.",
+		ConstLabels: labels,
+	}, func() float64 {
+		return float64(client.RunningThreads())
+	})
+	target := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "target_ops",
+		Help:        "Current target throughput in ops/s, 0 means unthrottled.",
+		ConstLabels: labels,
+	}, client.CurrentTarget)
+
+	return o, []prometheus.Collector{o.ops, o.errs, o.durations, threads, target}
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserver(t *testing.T) {
+	p := properties.NewProperties()
+	p.Set(prop.ReportLatencyForEachError, "true")
+	measurement.InitMeasure(p)
+
+	o, collectors := newCollectors(prometheus.Labels{"label": "test", "db": "basic", "command": "run"})
+	prometheus.NewRegistry().MustRegister(collectors...)
+	measurement.AddObserver(o)
+
+	start := time.Now()
+	measurement.Measure("READ", start, time.Millisecond)
+	measurement.MeasureError(-1, "READ", "TIMEOUT", "i/o timeout", start, time.Second)
+	measurement.MeasureError(-1, "READ", "OTHER", "failed", start, time.Second)
+
+	// the operations measured from the intended start and the steps of the
+	// transactions are counted once
+	measurement.Measure("INTENDED_READ", start, time.Second)
+	measurement.Measure("TXN_READ", start, time.Millisecond)
+	measurement.Measure("COMMIT", start, time.Millisecond)
+	measurement.Measure("TXN", start, 2*time.Millisecond)
+	measurement.MeasureError(-1, "COMMIT", "CONFLICT", "write conflict", start, time.Millisecond)
+
+	if n := testutil.ToFloat64(o.ops.WithLabelValues("READ")); n != 3 {
+		t.Fatalf("want 3 operations, but got %v", n)
+	}
+	// the classed errors are measured as READ_TIMEOUT_ERROR but exported by the class
+	if n := testutil.ToFloat64(o.errs.WithLabelValues("READ", "TIMEOUT")); n != 1 {
+		t.Fatalf("want 1 timeout, but got %v", n)
+	}
+	if n := testutil.ToFloat64(o.ops.WithLabelValues("TXN")); n != 2 {
+		t.Fatalf("want 2 transactions, but got %v", n)
+	}
+	if n := testutil.ToFloat64(o.errs.WithLabelValues("TXN", "CONFLICT")); n != 1 {
+		t.Fatalf("want 1 failed transaction, but got %v", n)
+	}
+	if n := testutil.CollectAndCount(o.ops); n != 2 {
+		t.Fatalf("want the READ and TXN operations, but got %d", n)
+	}
+	if n := testutil.CollectAndCount(o.errs); n != 3 {
+		t.Fatalf("want 3 error series, but got %d", n)
+	}
+	if n := testutil.CollectAndCount(o.durations); n != 4 {
+		t.Fatalf("want the ok and error durations of READ and TXN, but got %d", n)
+	}
+	if _, ok := measurement.Info()["READ_TIMEOUT_ERROR"]; !ok {
+		t.Fatalf("the timeout isn't measured separately")
+	}
+}
+
+func TestInitTwice(t *testing.T) {
+	p := properties.NewProperties()
+	measurement.InitMeasure(p)
+	Init(p, "basic")
+	Init(p, "basic")
+}
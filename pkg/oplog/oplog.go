@@ -0,0 +1,198 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oplog records the operations of a run to an operation log, which
+// the replay workload plays back against any database, so a production
+// access pattern can be benchmarked instead of a synthetic one.
+//
+// An operation log has a JSON record per line, e.g.
+//
+//	{"offset_us":1200,"op":"UPDATE","table":"usertable","key":"user1","fields":["field0"],"value_size":100}
+package oplog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// The operations in an operation log.
+const (
+	OpRead        = "READ"
+	OpUpdate      = "UPDATE"
+	OpInsert      = "INSERT"
+	OpDelete      = "DELETE"
+	OpScan        = "SCAN"
+	OpReverseScan = "REVERSE_SCAN"
+)
+
+// Record is an operation in an operation log.
+type Record struct {
+	// Offset is when the operation starts since the log starts in us, the
+	// records without it are replayed as fast as possible.
+	Offset int64  `json:"offset_us,omitempty"`
+	Op     string `json:"op"`
+	Table  string `json:"table"`
+	// Key is the key of the operation, the start key of a scan or the end
+	// key of a reverse scan.
+	Key string `json:"key"`
+	// Bound is the other bound of a range scan, the end key of a scan or the
+	// start key of a reverse scan, empty means unbounded.
+	Bound string `json:"bound,omitempty"`
+	// Fields are the fields read or written, empty means all fields.
+	Fields []string `json:"fields,omitempty"`
+	// ValueSize is the size of every field value written.
+	ValueSize int `json:"value_size,omitempty"`
+	// Count is the number of records to scan.
+	Count int `json:"count,omitempty"`
+}
+
+func (r *Record) validate() error {
+	switch r.Op {
+	case OpRead, OpUpdate, OpInsert, OpDelete, OpScan, OpReverseScan:
+	default:
+		return fmt.Errorf("unknown op %q", r.Op)
+	}
+	if r.Table == "" {
+		return fmt.Errorf("no table")
+	}
+	if r.Key == "" && r.Op != OpReverseScan {
+		return fmt.Errorf("no key")
+	}
+	if r.ValueSize < 0 || r.Count < 0 {
+		return fmt.Errorf("negative value size or count")
+	}
+	return nil
+}
+
+// Reader reads the records of an operation log.
+type Reader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// NewReader returns a reader of the operation log in r.
+func NewReader(r io.Reader) *Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &Reader{s: s}
+}
+
+// Next returns the next record, or io.EOF at the end of the log.
+func (r *Reader) Next() (*Record, error) {
+	for r.s.Scan() {
+		r.line++
+		line := r.s.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		rec := new(Record)
+		if err := json.Unmarshal(line, rec); err != nil {
+			return nil, fmt.Errorf("line %d: %v", r.line, err)
+		}
+		if err := rec.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %v", r.line, err)
+		}
+		return rec, nil
+	}
+	if err := r.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// recorder writes the records of a run.
+type recorder struct {
+	start time.Time
+
+	mu  sync.Mutex
+	f   *os.File
+	w   *bufio.Writer
+	enc *json.Encoder
+	err error
+}
+
+var globalRecorder *recorder
+
+// Init starts recording the operations if record.file is set.
+func Init(p *properties.Properties) {
+	path := p.GetString(prop.RecordFile, "")
+	if path == "" {
+		return
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		util.Fatalf("create operation log %s failed %v", path, err)
+	}
+	w := bufio.NewWriterSize(f, 64*1024)
+	globalRecorder = &recorder{
+		start: time.Now(),
+		f:     f,
+		w:     w,
+		enc:   json.NewEncoder(w),
+	}
+}
+
+// Enabled returns whether the operations are recorded.
+func Enabled() bool {
+	return globalRecorder != nil
+}
+
+// Log records the operation which starts at start, its offset is set by
+// the recorder.
+func Log(start time.Time, r *Record) {
+	l := globalRecorder
+	if l == nil {
+		return
+	}
+	r.Offset = start.Sub(l.start).Microseconds()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.enc == nil || l.err != nil {
+		return
+	}
+	if l.err = l.enc.Encode(r); l.err != nil {
+		fmt.Fprintf(os.Stderr, "oplog: write failed %v, stop recording\n", l.err)
+	}
+}
+
+// Close flushes and closes the operation log.
+func Close() {
+	l := globalRecorder
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.enc == nil {
+		return
+	}
+	l.enc = nil
+	if err := l.w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "oplog: flush failed %v\n", err)
+	}
+	if err := l.f.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "oplog: close failed %v\n", err)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oplog
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ops.jsonl")
+	p := properties.NewProperties()
+	p.Set(prop.RecordFile, path)
+	Init(p)
+	defer func() { globalRecorder = nil }()
+
+	start := globalRecorder.start
+	want := []Record{
+		{Offset: 0, Op: OpInsert, Table: "usertable", Key: "user1", Fields: []string{"field0", "field1"}, ValueSize: 100},
+		{Offset: 1500, Op: OpScan, Table: "usertable", Key: "user1", Count: 10},
+	}
+	for i := range want {
+		r := want[i]
+		Log(start.Add(time.Duration(r.Offset)*time.Microsecond), &r)
+	}
+	Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	reader := NewReader(f)
+	for _, w := range want {
+		r, err := reader.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*r, w) {
+			t.Fatalf("want %+v, got %+v", w, *r)
+		}
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Fatalf("want EOF, got %v", err)
+	}
+}
+
+func TestReadBadRecord(t *testing.T) {
+	log := `{"op":"READ","table":"usertable","key":"user1"}
+
+{"op":"MERGE","table":"usertable","key":"user1"}
+`
+	reader := NewReader(strings.NewReader(log))
+	if _, err := reader.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.Next(); err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Fatalf("want the error of line 3, got %v", err)
+	}
+}
@@ -29,6 +29,9 @@ const (
 	ThreadCount        = "threadcount"
 	ThreadCountDefault = int64(200)
 	Target             = "target"
+	OpenLoop           = "openloop"
+	OpenLoopDefault    = false
+	Phases             = "phases"
 	MaxExecutiontime   = "maxexecutiontime"
 	WarmUpTime         = "warmuptime"
 	DoTransactions     = "dotransactions"
@@ -38,6 +41,51 @@ const (
 	BatchSize        = "batch.size"
 	DefaultBatchSize = int(1)
 
+	// adaptive warm-up
+	WarmUpAdaptive        = "warmup.adaptive"
+	WarmUpAdaptiveDefault = false
+	WarmUpInterval        = "warmup.interval"
+	WarmUpIntervalDefault = int64(1)
+	WarmUpWindow          = "warmup.window"
+	WarmUpWindowDefault   = int64(10)
+	WarmUpMaxCV           = "warmup.cv"
+	WarmUpMaxCVDefault    = 0.05
+	WarmUpMaxTime         = "warmup.maxtime"
+	WarmUpMaxTimeDefault  = int64(600)
+
+	// the resource usage of the client
+	ClientMonitor        = "clientmonitor"
+	ClientMonitorDefault = true
+
+	// stop conditions besides maxexecutiontime and operationcount
+	StopMaxErrors              = "stop.maxerrors"
+	StopErrorRate              = "stop.errorrate"
+	StopErrorRateWindow        = "stop.errorrate.window"
+	StopErrorRateWindowDefault = int64(10)
+	StopErrorRateMinOps        = "stop.errorrate.minops"
+	StopErrorRateMinOpsDefault = int64(100)
+
+	// saturation search
+	SearchMode                    = "search.mode"
+	SearchModeDefault             = "target"
+	SearchStart                   = "search.start"
+	SearchStartTargetDefault      = int64(1000)
+	SearchStartThreadsDefault     = int64(1)
+	SearchFactor                  = "search.factor"
+	SearchFactorDefault           = float64(2)
+	SearchPrecision               = "search.precision"
+	SearchPrecisionDefault        = float64(5)
+	SearchMaxSteps                = "search.maxsteps"
+	SearchMaxStepsDefault         = int(20)
+	SearchStepTime                = "search.steptime"
+	SearchStepTimeDefault         = int64(60)
+	SearchSLOP99                  = "search.slo.p99"
+	SearchSLOP999                 = "search.slo.p999"
+	SearchSLOErrorRate            = "search.slo.errorrate"
+	SearchSLOErrorRateDefault     = float64(1)
+	SearchSLOMinThroughput        = "search.slo.minthroughput"
+	SearchSLOMinThroughputDefault = float64(0.9)
+
 	TableName         = "table"
 	TableNameDefault  = "usertable"
 	FieldCount        = "fieldcount"
@@ -66,6 +114,12 @@ const (
 	ScanProportionDefault            = float64(0.0)
 	ReadModifyWriteProportion        = "readmodifywriteproportion"
 	ReadModifyWriteProportionDefault = float64(0.0)
+	DeleteProportion                 = "deleteproportion"
+	DeleteProportionDefault          = float64(0.0)
+	ScanReverseProportion            = "scanreverseproportion"
+	ScanReverseProportionDefault     = float64(0.0)
+	ScanEndBounded                   = "scanendbounded"
+	ScanEndBoundedDefault            = false
 	// "uniform", "zipfian", "latest"
 	RequestDistribution        = "requestdistribution"
 	RequestDistributionDefault = "uniform"
@@ -113,6 +167,56 @@ const (
 	MeasurementTypeDefault   = "histogram"
 	MeasurementRawOutputFile = "measurement.output_file"
 
+	ReportLatencyForEachError        = "reportlatencyforeacherror"
+	ReportLatencyForEachErrorDefault = false
+	LatencyTrackedErrors             = "latencytrackederrors"
+	TopErrors                        = "measurement.top_errors"
+	TopErrorsDefault                 = 10
+
+	MeasurementRawCompress          = "measurement.raw.compress"
+	MeasurementRawCompressDefault   = false
+	MeasurementRawRotateSize        = "measurement.raw.rotate_size"
+	MeasurementRawRotateSizeDefault = int64(0)
+
+	MeasurementHdrLogOutputFile        = "measurement.hdrlog.output_file"
+	MeasurementHdrLogOutputFileDefault = "go-ycsb.hlog"
+
+	HistogramPercentiles              = "measurement.histogram.percentiles"
+	HistogramPercentilesDefault       = "99,99.9,99.99"
+	HistogramTimeUnit                 = "measurement.histogram.timeunit"
+	HistogramTimeUnitDefault          = "us"
+	HistogramSignificantDigits        = "measurement.histogram.significant_digits"
+	HistogramSignificantDigitsDefault = 3
+
+	TracingExporter                  = "tracing.exporter"
+	TracingOTLPEndpoint              = "tracing.otlp.endpoint"
+	TracingOTLPEndpointDefault       = "http://localhost:4318/v1/traces"
+	TracingFile                      = "tracing.file"
+	TracingFileDefault               = "go-ycsb-spans.json"
+	TracingSamplerProbability        = "tracing.sampler.probability"
+	TracingSamplerProbabilityDefault = 0.01
+
+	SlowLogThreshold        = "slowlog.threshold"
+	SlowLogFile             = "slowlog.file"
+	SlowLogFileDefault      = "go-ycsb-slow.log"
+	SlowLogRateLimit        = "slowlog.ratelimit"
+	SlowLogRateLimitDefault = int64(100)
+
+	RecordFile = "record.file"
+
+	HistoryFile = "history.file"
+
+	ReplayFile          = "replay.file"
+	ReplayTiming        = "replay.timing"
+	ReplayTimingDefault = "asap"
+
+	ClosedEconomyInitialBalance        = "closedeconomy.initialbalance"
+	ClosedEconomyInitialBalanceDefault = int64(1000)
+	ClosedEconomyKeys                  = "closedeconomy.keys"
+	ClosedEconomyKeysDefault           = 2
+	ClosedEconomyMaxTransfer           = "closedeconomy.maxtransfer"
+	ClosedEconomyMaxTransferDefault    = int64(100)
+
 	Command = "command"
 
 	OutputStyle = "outputstyle"
@@ -0,0 +1,200 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	chartWidth  = 760
+	chartHeight = 300
+	chartLeft   = 70
+	chartRight  = 170
+	chartTop    = 30
+	chartBottom = 40
+)
+
+var chartColors = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f",
+	"#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac",
+}
+
+type chartSeries struct {
+	name   string
+	points []Point
+}
+
+type chartTick struct {
+	value float64
+	label string
+}
+
+// chart is a line chart rendered as inline SVG, so the page needs no script.
+type chart struct {
+	title  string
+	xLabel string
+	yLabel string
+	series []chartSeries
+	// xTicks are computed from the points if not set.
+	xTicks []chartTick
+}
+
+// newThroughputChart charts the OPS over time of the series.
+func newThroughputChart(title string, series []chartSeries) *chart {
+	return &chart{title: title, xLabel: "Time(s)", yLabel: "OPS", series: series}
+}
+
+// newPercentileChart charts the latencies over the percentiles of the
+// series. The percentiles are spread by their nines, so 90, 99, 99.9 and so
+// on are evenly spaced and the maximum comes last.
+func newPercentileChart(title string, series []chartSeries) *chart {
+	nines := 2.0
+	for _, s := range series {
+		for _, p := range s.points {
+			if p.X < 100 {
+				nines = math.Max(nines, math.Ceil(percentileNines(p.X)))
+			}
+		}
+	}
+	maxX := nines + 1
+
+	c := &chart{title: title, xLabel: "Percentile", yLabel: "Latency(us)"}
+	for _, s := range series {
+		points := make([]Point, 0, len(s.points))
+		for _, p := range s.points {
+			x := maxX
+			if p.X < 100 {
+				x = percentileNines(p.X)
+			}
+			points = append(points, Point{X: x, Y: p.Y})
+		}
+		c.series = append(c.series, chartSeries{name: s.name, points: points})
+	}
+
+	c.xTicks = append(c.xTicks, chartTick{0, "0"})
+	for i := 1.0; i <= nines; i++ {
+		p := 100 - 100/math.Pow(10, i)
+		c.xTicks = append(c.xTicks, chartTick{i, strconv.FormatFloat(p, 'f', int(math.Max(0, i-2)), 64)})
+	}
+	c.xTicks = append(c.xTicks, chartTick{maxX, "max"})
+	return c
+}
+
+// percentileNines returns the number of nines of the percentile, like 2 for
+// 99 and 3 for 99.9.
+func percentileNines(p float64) float64 {
+	return -math.Log10(1 - p/100)
+}
+
+// niceStep returns a step of 1, 2 or 5 times a power of 10 to split the
+// range into about n ticks.
+func niceStep(max float64, n int) float64 {
+	if max <= 0 {
+		return 1
+	}
+	raw := max / float64(n)
+	pow := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5} {
+		if raw <= m*pow {
+			return m * pow
+		}
+	}
+	return 10 * pow
+}
+
+func linearTicks(max float64) []chartTick {
+	step := niceStep(max, 5)
+	decimals := int(math.Max(0, -math.Floor(math.Log10(step))))
+	ticks := []chartTick{{0, "0"}}
+	for i := 1; ticks[len(ticks)-1].value < max; i++ {
+		v := float64(i) * step
+		ticks = append(ticks, chartTick{v, strconv.FormatFloat(v, 'f', decimals, 64)})
+	}
+	return ticks
+}
+
+// svg renders the chart.
+func (c *chart) svg() template.HTML {
+	maxX, maxY := 0.0, 0.0
+	for _, s := range c.series {
+		for _, p := range s.points {
+			maxX = math.Max(maxX, p.X)
+			maxY = math.Max(maxY, p.Y)
+		}
+	}
+	xTicks := c.xTicks
+	if xTicks == nil {
+		xTicks = linearTicks(maxX)
+	}
+	yTicks := linearTicks(maxY)
+	maxX = math.Max(maxX, xTicks[len(xTicks)-1].value)
+	maxY = yTicks[len(yTicks)-1].value
+	if maxX == 0 {
+		maxX = 1
+	}
+	if maxY == 0 {
+		maxY = 1
+	}
+
+	plotW := float64(chartWidth - chartLeft - chartRight)
+	plotH := float64(chartHeight - chartTop - chartBottom)
+	px := func(x float64) float64 { return chartLeft + x/maxX*plotW }
+	py := func(y float64) float64 { return chartTop + plotH - y/maxY*plotH }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		chartWidth, chartHeight, chartWidth, chartHeight)
+	fmt.Fprintf(&b, `<text x="%d" y="18" class="title">%s</text>`, chartLeft, html.EscapeString(c.title))
+
+	for _, t := range yTicks {
+		y := py(t.value)
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%.1f" y2="%.1f" class="grid"/>`, chartLeft, y, px(maxX), y)
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" class="ytick">%s</text>`, chartLeft-6, y+4, t.label)
+	}
+	for _, t := range xTicks {
+		x := px(t.value)
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" class="axis"/>`, x, py(0), x, py(0)+4)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" class="xtick">%s</text>`, x, py(0)+16, html.EscapeString(t.label))
+	}
+	fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%.1f" y2="%.1f" class="axis"/>`, chartLeft, py(0), px(maxX), py(0))
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%.1f" class="axis"/>`, chartLeft, chartTop, chartLeft, py(0))
+	fmt.Fprintf(&b, `<text x="%.1f" y="%d" class="xlabel">%s</text>`, px(maxX/2), chartHeight-4, html.EscapeString(c.xLabel))
+	fmt.Fprintf(&b, `<text x="14" y="%.1f" class="ylabel" transform="rotate(-90 14 %.1f)">%s</text>`,
+		py(maxY/2), py(maxY/2), html.EscapeString(c.yLabel))
+
+	for i, s := range c.series {
+		color := chartColors[i%len(chartColors)]
+		coords := make([]string, 0, len(s.points))
+		for _, p := range s.points {
+			coords = append(coords, fmt.Sprintf("%.1f,%.1f", px(p.X), py(p.Y)))
+		}
+		fmt.Fprintf(&b, `<polyline points="%s" stroke="%s" class="line"/>`, strings.Join(coords, " "), color)
+		if len(s.points) == 1 {
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`, px(s.points[0].X), py(s.points[0].Y), color)
+		}
+
+		ly := chartTop + 16*i
+		lx := chartWidth - chartRight + 16
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="10" height="10" fill="%s"/>`, lx, ly, color)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" class="legend">%s</text>`, lx+14, ly+9, html.EscapeString(s.name))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
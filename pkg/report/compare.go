@@ -0,0 +1,370 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Metrics of the operations, the first four are compared.
+const (
+	MetricOPS   = "OPS"
+	MetricAvg   = "Avg(us)"
+	MetricP99   = "99th(us)"
+	MetricP999  = "99.9th(us)"
+	MetricP9999 = "99.99th(us)"
+	MetricMin   = "Min(us)"
+	MetricMax   = "Max(us)"
+	MetricCount = "Count"
+	MetricTakes = "Takes(s)"
+	MetricMBps  = "MB/s"
+	MetricSize  = "Avg Size(B)"
+)
+
+var comparedMetrics = []string{MetricOPS, MetricAvg, MetricP99, MetricP999}
+
+// topErrorsSection is the section of the top error messages in the outputs,
+// its rows aren't operations.
+const topErrorsSection = "TOP ERRORS"
+
+// Result is the summary of every operation of a saved run, keyed by the
+// section and then by the operation.
+type Result struct {
+	Sections []string
+	Ops      map[string]map[string]map[string]float64
+}
+
+func newResult() *Result {
+	return &Result{Ops: make(map[string]map[string]map[string]float64)}
+}
+
+func (r *Result) set(section string, op string, metrics map[string]float64) {
+	ops, ok := r.Ops[section]
+	if !ok {
+		ops = make(map[string]map[string]float64)
+		r.Ops[section] = ops
+		r.Sections = append(r.Sections, section)
+	}
+	// a later summary of the same operation, like the final output after the
+	// periodic ones, replaces the earlier one.
+	ops[op] = metrics
+}
+
+// LoadResult loads the result from a JSON report written by --report, the
+// output of the json exporter, or the captured output of a run with the
+// plain or json outputstyle.
+func LoadResult(path string) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r *Result
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		r, err = parseReport(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("[{\"name\"")), bytes.HasPrefix(trimmed, []byte("[\n")):
+		r, err = parseExport(trimmed)
+	default:
+		r, _, err = parseOutput(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s failed: %v", path, err)
+	}
+	if len(r.Sections) == 0 {
+		return nil, fmt.Errorf("no operation found in %s", path)
+	}
+	return r, nil
+}
+
+func parseReport(data []byte) (*Result, error) {
+	var rep Report
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return nil, err
+	}
+	if rep.Version > Version {
+		return nil, fmt.Errorf("unsupported report version %d", rep.Version)
+	}
+
+	r := newResult()
+	for _, s := range rep.Sections {
+		for _, op := range s.Operations {
+			metrics := map[string]float64{
+				MetricOPS:   op.OPS,
+				MetricAvg:   float64(op.Avg),
+				MetricP99:   float64(op.P99),
+				MetricP999:  float64(op.P999),
+				MetricP9999: float64(op.P9999),
+				MetricMin:   float64(op.Min),
+				MetricMax:   float64(op.Max),
+				MetricCount: float64(op.Count),
+				MetricTakes: op.Elapsed,
+			}
+			payload := op.BytesRead + op.BytesWritten
+			if op.Elapsed > 0 {
+				metrics[MetricMBps] = float64(payload) / op.Elapsed / (1 << 20)
+			}
+			if op.Count > 0 {
+				metrics[MetricSize] = float64(payload / op.Count)
+			}
+			r.set(s.Name, op.Name, metrics)
+		}
+	}
+	return r, nil
+}
+
+func parseExport(data []byte) (*Result, error) {
+	var sections []struct {
+		Name       string              `json:"name"`
+		Operations []map[string]string `json:"operations"`
+	}
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return nil, err
+	}
+
+	r := newResult()
+	for _, s := range sections {
+		if s.Name == topErrorsSection {
+			continue
+		}
+		for _, op := range s.Operations {
+			if err := setRow(r, s.Name, op); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+var (
+	sectionLine = regexp.MustCompile(`^\*\*\*\*\* (.+) \*\*\*\*\*$`)
+	plainLine   = regexp.MustCompile(`^(\S+)\s+- (Takes\(s\): .*)$`)
+)
+
+// parseOutput parses the summaries in the output of a run, the sections
+// start with a line like "***** PHASE 1: ... *****". It also returns the
+// throughput over time of every operation from the periodic summaries.
+func parseOutput(data []byte) (*Result, map[string][]Point, error) {
+	r := newResult()
+	tp := newThroughput()
+	section := ""
+	finished := false
+	addRow := func(row map[string]string) error {
+		if section == topErrorsSection {
+			return nil
+		}
+		if err := setRow(r, section, row); err != nil {
+			return err
+		}
+		if !finished {
+			tp.add(row)
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if m := sectionLine.FindStringSubmatch(line); m != nil {
+			section = m[1]
+			continue
+		}
+		if strings.HasPrefix(line, "Run finished") {
+			finished = true
+			continue
+		}
+
+		if strings.HasPrefix(line, "[{") {
+			var rows []map[string]string
+			if err := json.Unmarshal([]byte(line), &rows); err != nil {
+				return nil, nil, err
+			}
+			for _, row := range rows {
+				if err := addRow(row); err != nil {
+					return nil, nil, err
+				}
+			}
+			continue
+		}
+
+		if m := plainLine.FindStringSubmatch(line); m != nil {
+			row := map[string]string{"Operation": m[1]}
+			for _, kv := range strings.Split(m[2], ", ") {
+				seps := strings.SplitN(kv, ": ", 2)
+				if len(seps) == 2 {
+					row[seps[0]] = seps[1]
+				}
+			}
+			if err := addRow(row); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// the periodic summaries before the sections aren't part of the result
+	if len(r.Sections) > 1 && r.Sections[0] == "" {
+		delete(r.Ops, "")
+		r.Sections = r.Sections[1:]
+	}
+	return r, tp.points, nil
+}
+
+func setRow(r *Result, section string, row map[string]string) error {
+	op := row["Operation"]
+	if op == "" {
+		return nil
+	}
+
+	metrics, err := rowMetrics(row)
+	if err != nil || metrics == nil {
+		return err
+	}
+	r.set(section, op, metrics)
+	return nil
+}
+
+// latencyUnits are the factors to convert the latencies printed in the
+// time unit of measurement.histogram.timeunit to us.
+var latencyUnits = map[string]float64{"(ns)": 0.001, "(us)": 1, "(ms)": 1000}
+
+// rowMetrics returns the numeric fields of the row with the latencies in us,
+// only the compared metrics must be numbers. It returns nil if the row has
+// none of the compared metrics.
+func rowMetrics(row map[string]string) (map[string]float64, error) {
+	metrics := make(map[string]float64, len(row))
+	for name, v := range row {
+		f, err := strconv.ParseFloat(v, 64)
+		if err == nil {
+			if n := len(name) - len("(us)"); n > 0 {
+				if factor, ok := latencyUnits[name[n:]]; ok {
+					name, f = name[:n]+"(us)", f*factor
+				}
+			}
+			metrics[name] = f
+			continue
+		}
+		for _, m := range comparedMetrics {
+			if name == m {
+				return nil, fmt.Errorf("bad %s of %s: %v", name, row["Operation"], err)
+			}
+		}
+	}
+	for _, m := range comparedMetrics {
+		if _, ok := metrics[m]; ok {
+			return metrics, nil
+		}
+	}
+	return nil, nil
+}
+
+// Thresholds are the changes in percent beyond which a metric regresses, a
+// negative threshold disables the check.
+type Thresholds struct {
+	// OPS is the maximum drop of the throughput.
+	OPS float64
+	// Avg, P99 and P999 are the maximum increases of the latencies.
+	Avg  float64
+	P99  float64
+	P999 float64
+}
+
+func (t Thresholds) of(metric string) float64 {
+	switch metric {
+	case MetricOPS:
+		return t.OPS
+	case MetricAvg:
+		return t.Avg
+	case MetricP99:
+		return t.P99
+	default:
+		return t.P999
+	}
+}
+
+// Delta is the change of a metric of an operation.
+type Delta struct {
+	Section   string
+	Operation string
+	Metric    string
+	Base      float64
+	Current   float64
+	// Change is in percent of Base, an increase from 0 counts as 100%.
+	Change     float64
+	Regression bool
+}
+
+// Compare compares the current result to the base for every operation in
+// both of them, the failed operations are skipped.
+func Compare(base *Result, current *Result, t Thresholds) []Delta {
+	var deltas []Delta
+	for _, section := range base.Sections {
+		curOps, ok := current.Ops[section]
+		if !ok {
+			continue
+		}
+
+		baseOps := base.Ops[section]
+		names := make([]string, 0, len(baseOps))
+		for name := range baseOps {
+			if _, ok := curOps[name]; ok && !strings.HasSuffix(name, "_ERROR") {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			for _, metric := range comparedMetrics {
+				b, ok1 := baseOps[name][metric]
+				c, ok2 := curOps[name][metric]
+				if !ok1 || !ok2 {
+					continue
+				}
+				deltas = append(deltas, newDelta(section, name, metric, b, c, t.of(metric)))
+			}
+		}
+	}
+	return deltas
+}
+
+func newDelta(section string, op string, metric string, base float64, current float64, threshold float64) Delta {
+	d := Delta{Section: section, Operation: op, Metric: metric, Base: base, Current: current}
+	switch {
+	case base != 0:
+		d.Change = (current - base) * 100 / base
+	case current != 0:
+		d.Change = 100
+	}
+
+	if threshold >= 0 {
+		if metric == MetricOPS {
+			d.Regression = -d.Change > threshold
+		} else {
+			d.Regression = d.Change > threshold
+		}
+	}
+	return d
+}
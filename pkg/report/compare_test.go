@@ -0,0 +1,91 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOutput(t *testing.T) {
+	out := `"target"="1000"
+READ   - Takes(s): 1.0, Count: 10, OPS: 10.0, Avg(us): 1, Min(us): 0, Max(us): 1, 99th(us): 1, 99.9th(us): 1, 99.99th(us): 1, Intv OPS: 10.0
+Run finished, takes 2s
+***** PHASE 1: 1s at 1000 ops/s *****
+READ   - Takes(s): 1.0, Count: 1000, OPS: 1000.0, Avg(us): 5, Min(us): 0, Max(us): 9, 99th(us): 8, 99.9th(us): 9, 99.99th(us): 9
+***** PHASE 2: 1s at 2000 ops/s *****
+[{"99.99th(us)":"20","99.9th(us)":"19","99th(us)":"18","Avg(us)":"10","Count":"2000","Max(us)":"20","Min(us)":"1","OPS":"2000.0","Operation":"READ","Takes(s)":"1.0"}]
+`
+	r, tp, err := parseOutput([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sections := []string{"PHASE 1: 1s at 1000 ops/s", "PHASE 2: 1s at 2000 ops/s"}
+	if !reflect.DeepEqual(r.Sections, sections) {
+		t.Fatalf("want sections %v, but got %v", sections, r.Sections)
+	}
+	want := map[string]float64{MetricOPS: 2000, MetricAvg: 10, MetricP99: 18, MetricP999: 19,
+		MetricP9999: 20, MetricMin: 1, MetricMax: 20, MetricCount: 2000, MetricTakes: 1}
+	if got := r.Ops[sections[1]]["READ"]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, but got %v", want, got)
+	}
+
+	// only the periodic summaries count for the throughput over time
+	wantTp := map[string][]Point{"READ": {{X: 1, Y: 10}}}
+	if !reflect.DeepEqual(tp, wantTp) {
+		t.Fatalf("want throughput %v, but got %v", wantTp, tp)
+	}
+}
+
+func TestParseOutputWithErrors(t *testing.T) {
+	out := `[{"99.99th(us)":"20","99.9th(us)":"19","99th(us)":"18","Avg(us)":"10","Count":"2000","Max(us)":"20","Min(us)":"1","OPS":"2000.0","Operation":"READ","Takes(s)":"1.0"},{"Count":"3","OPS":"3.0","Operation":"READ_ERROR","Takes(s)":"1.0"}]
+***** TOP ERRORS *****
+[{"Class":"timeout","Count":"3","Error":"i/o timeout","Operation":"READ"}]
+`
+	r, _, err := parseOutput([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{""}; !reflect.DeepEqual(r.Sections, want) {
+		t.Fatalf("want sections %v, but got %v", want, r.Sections)
+	}
+	if got := r.Ops[""]["READ"][MetricOPS]; got != 2000 {
+		t.Fatalf("want READ OPS 2000, but got %v", got)
+	}
+	if got := r.Ops[""]["READ_ERROR"][MetricCount]; got != 3 {
+		t.Fatalf("want READ_ERROR count 3, but got %v", got)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	base := newResult()
+	base.set("", "READ", map[string]float64{MetricOPS: 1000, MetricAvg: 10, MetricP99: 0, MetricP999: 100})
+	base.set("", "READ_ERROR", map[string]float64{MetricOPS: 1})
+	cur := newResult()
+	cur.set("", "READ", map[string]float64{MetricOPS: 900, MetricAvg: 11, MetricP99: 1, MetricP999: 150})
+	cur.set("", "READ_ERROR", map[string]float64{MetricOPS: 100})
+
+	deltas := Compare(base, cur, Thresholds{OPS: 5, Avg: 10, P99: -1, P999: 50})
+	want := []Delta{
+		{Operation: "READ", Metric: MetricOPS, Base: 1000, Current: 900, Change: -10, Regression: true},
+		{Operation: "READ", Metric: MetricAvg, Base: 10, Current: 11, Change: 10},
+		{Operation: "READ", Metric: MetricP99, Base: 0, Current: 1, Change: 100},
+		{Operation: "READ", Metric: MetricP999, Base: 100, Current: 150, Change: 50},
+	}
+	if !reflect.DeepEqual(deltas, want) {
+		t.Fatalf("want %v, but got %v", want, deltas)
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// summaryMetrics are the columns of the summary tables.
+var summaryMetrics = []string{MetricCount, MetricOPS, MetricMBps, MetricSize, MetricAvg, MetricP99, MetricP999, MetricP9999, MetricMax}
+
+// workloadRuns are the runs of a workload, the runs without workload are
+// grouped together.
+type workloadRuns struct {
+	name     string
+	runs     []*Run
+	sections []string
+	ops      []string
+}
+
+func groupRuns(runs []*Run) []*workloadRuns {
+	var groups []*workloadRuns
+	byName := make(map[string]*workloadRuns)
+	for _, run := range runs {
+		g, ok := byName[run.Workload]
+		if !ok {
+			g = &workloadRuns{name: run.Workload}
+			byName[run.Workload] = g
+			groups = append(groups, g)
+		}
+		g.runs = append(g.runs, run)
+	}
+
+	for _, g := range groups {
+		sections := make(map[string]bool)
+		ops := make(map[string]bool)
+		for _, run := range g.runs {
+			for _, section := range run.Sections {
+				if !sections[section] {
+					sections[section] = true
+					g.sections = append(g.sections, section)
+				}
+				for op := range run.Ops[section] {
+					ops[op] = true
+				}
+			}
+		}
+		for op := range ops {
+			g.ops = append(g.ops, op)
+		}
+		sort.Strings(g.ops)
+	}
+	return groups
+}
+
+func (g *workloadRuns) title() string {
+	if g.name == "" {
+		return "Runs"
+	}
+	return g.name
+}
+
+// summary returns the table of the operations of every database in the
+// section.
+func (g *workloadRuns) summary(section string) ([]string, [][]string) {
+	header := append([]string{"Operation", "DB"}, summaryMetrics...)
+	var rows [][]string
+	for _, op := range g.ops {
+		for _, run := range g.runs {
+			metrics, ok := run.Ops[section][op]
+			if !ok {
+				continue
+			}
+			row := []string{op, run.DB}
+			for _, name := range summaryMetrics {
+				if v, ok := metrics[name]; !ok {
+					row = append(row, "-")
+				} else if name == MetricOPS || name == MetricMBps {
+					row = append(row, util.FloatToOneString(v))
+				} else {
+					row = append(row, util.IntToString(int64(v)))
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	return header, rows
+}
+
+// charted returns the operations which are charted, the failed operations
+// only show in the tables.
+func (g *workloadRuns) charted() []string {
+	var ops []string
+	for _, op := range g.ops {
+		if !strings.HasSuffix(op, "_ERROR") {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+func sectionTitle(section string, s string) string {
+	if section == "" {
+		return s
+	}
+	return section + " " + s
+}
+
+type htmlTable struct {
+	Section string
+	Header  []string
+	Rows    [][]string
+}
+
+type htmlGroup struct {
+	Name   string
+	Charts []template.HTML
+}
+
+type htmlWorkload struct {
+	Name   string
+	Tables []htmlTable
+	ByOp   []htmlGroup
+	ByDB   []htmlGroup
+}
+
+// chartsOf charts the throughput and the latencies of the series picked
+// from the runs.
+func chartsOf(g *workloadRuns, name string, pick func(run *Run, op string) bool, label func(run *Run, op string) string) []template.HTML {
+	var charts []template.HTML
+
+	var tp []chartSeries
+	for _, run := range g.runs {
+		for _, op := range g.charted() {
+			if points := run.Throughput[op]; pick(run, op) && len(points) > 0 {
+				tp = append(tp, chartSeries{name: label(run, op), points: points})
+			}
+		}
+	}
+	if len(tp) > 0 {
+		charts = append(charts, newThroughputChart(name+" throughput", tp).svg())
+	}
+
+	for _, section := range g.sections {
+		var lan []chartSeries
+		for _, run := range g.runs {
+			for _, op := range g.charted() {
+				if points := run.Percentiles(section, op); pick(run, op) && len(points) > 0 {
+					lan = append(lan, chartSeries{name: label(run, op), points: points})
+				}
+			}
+		}
+		if len(lan) > 0 {
+			charts = append(charts, newPercentileChart(sectionTitle(section, name+" latency"), lan).svg())
+		}
+	}
+	return charts
+}
+
+func newHTMLWorkload(g *workloadRuns) htmlWorkload {
+	w := htmlWorkload{Name: g.title()}
+	for _, section := range g.sections {
+		header, rows := g.summary(section)
+		w.Tables = append(w.Tables, htmlTable{Section: section, Header: header, Rows: rows})
+	}
+
+	for _, op := range g.charted() {
+		op := op
+		charts := chartsOf(g, op,
+			func(_ *Run, o string) bool { return o == op },
+			func(run *Run, _ string) string { return run.DB })
+		w.ByOp = append(w.ByOp, htmlGroup{Name: op, Charts: charts})
+	}
+
+	for _, run := range g.runs {
+		db := run
+		charts := chartsOf(g, db.DB,
+			func(r *Run, _ string) bool { return r == db },
+			func(_ *Run, op string) string { return op })
+		w.ByDB = append(w.ByDB, htmlGroup{Name: db.DB, Charts: charts})
+	}
+	return w
+}
+
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>go-ycsb report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #333; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th:nth-child(-n+2), td:nth-child(-n+2) { text-align: left; }
+svg { margin: 0 1em 1em 0; }
+svg .title { font-size: 14px; font-weight: bold; }
+svg .grid { stroke: #eee; }
+svg .axis { stroke: #999; }
+svg .line { fill: none; stroke-width: 1.5; }
+svg .xtick, svg .xlabel, svg .ylabel { font-size: 11px; text-anchor: middle; }
+svg .ytick { font-size: 11px; text-anchor: end; }
+svg .legend { font-size: 11px; }
+</style>
+</head>
+<body>
+<h1>go-ycsb report</h1>
+{{range .}}
+<h2>{{.Name}}</h2>
+{{range .Tables}}
+{{if .Section}}<h4>{{.Section}}</h4>{{end}}
+<table>
+<tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}
+<h3>By operation</h3>
+{{range .ByOp}}<h4>{{.Name}}</h4>
+<div>{{range .Charts}}{{.}}{{end}}</div>
+{{end}}
+<h3>By database</h3>
+{{range .ByDB}}<h4>{{.Name}}</h4>
+<div>{{range .Charts}}{{.}}{{end}}</div>
+{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes the summaries of the runs, and the charts of the
+// throughput over time and the latency percentiles per operation and per
+// database, as a self-contained HTML page.
+func WriteHTML(w io.Writer, runs []*Run) error {
+	var workloads []htmlWorkload
+	for _, g := range groupRuns(runs) {
+		workloads = append(workloads, newHTMLWorkload(g))
+	}
+	return htmlReport.Execute(w, workloads)
+}
+
+// WriteMarkdown writes the summaries of the runs as Markdown tables.
+func WriteMarkdown(w io.Writer, runs []*Run) error {
+	var b strings.Builder
+	b.WriteString("# go-ycsb report\n")
+	for _, g := range groupRuns(runs) {
+		fmt.Fprintf(&b, "\n## %s\n", g.title())
+		for _, section := range g.sections {
+			if section != "" {
+				fmt.Fprintf(&b, "\n### %s\n", section)
+			}
+			header, rows := g.summary(section)
+			fmt.Fprintf(&b, "\n|%s|\n", strings.Join(header, "|"))
+			fmt.Fprintf(&b, "|%s\n", strings.Repeat("-|", len(header)))
+			for _, row := range rows {
+				fmt.Fprintf(&b, "|%s|\n", strings.Join(row, "|"))
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
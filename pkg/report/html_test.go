@@ -0,0 +1,88 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func testRuns() []*Run {
+	newRun := func(db string, workload string, ops float64) *Run {
+		run := &Run{DB: db, Workload: workload, Result: newResult(), Throughput: make(map[string][]Point)}
+		run.set("", "READ", map[string]float64{MetricCount: 100, MetricOPS: ops, MetricAvg: 10,
+			MetricMin: 1, MetricMax: 50, MetricP99: 40})
+		run.set("", "READ_ERROR", map[string]float64{MetricCount: 2, MetricOPS: 0.5})
+		run.Throughput["READ"] = []Point{{X: 1, Y: ops}, {X: 2, Y: ops}}
+		run.Throughput["READ_ERROR"] = []Point{{X: 1, Y: 0.5}}
+		return run
+	}
+	return []*Run{newRun("tikv", "workloada", 1000), newRun("<mysql>", "workloada", 800.5)}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteMarkdown(&b, testRuns()); err != nil {
+		t.Fatal(err)
+	}
+	want := `# go-ycsb report
+
+## workloada
+
+|Operation|DB|Count|OPS|MB/s|Avg Size(B)|Avg(us)|99th(us)|99.9th(us)|99.99th(us)|Max(us)|
+|-|-|-|-|-|-|-|-|-|-|-|
+|READ|tikv|100|1000.0|-|-|10|40|-|-|50|
+|READ|<mysql>|100|800.5|-|-|10|40|-|-|50|
+|READ_ERROR|tikv|2|0.5|-|-|-|-|-|-|-|
+|READ_ERROR|<mysql>|2|0.5|-|-|-|-|-|-|-|
+`
+	if b.String() != want {
+		t.Fatalf("want\n%s\nbut got\n%s", want, b.String())
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteHTML(&b, testRuns()); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, s := range []string{
+		"<h2>workloada</h2>",
+		"<tr><td>READ</td><td>tikv</td><td>100</td><td>1000.0</td>",
+		// the names are escaped in the tables and the charts
+		"<td>&lt;mysql&gt;</td>",
+		`class="legend">&lt;mysql&gt;</text>`,
+		`class="title">READ throughput</text>`,
+		`class="title">READ latency</text>`,
+		`class="title">tikv throughput</text>`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("no %s in the HTML", s)
+		}
+	}
+	if strings.Contains(out, "<mysql>") {
+		t.Errorf("unescaped name in the HTML")
+	}
+	// the throughput and the latency of READ, and of every database, the
+	// failed operations aren't charted
+	if n := strings.Count(out, "<svg"); n != 6 {
+		t.Errorf("want 6 charts, but got %d", n)
+	}
+	if strings.Contains(out, `class="legend">READ_ERROR</text>`) {
+		t.Errorf("the failed operations are charted")
+	}
+}
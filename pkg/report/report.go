@@ -0,0 +1,230 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package report builds the machine-readable report of a run.
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/client"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// Version is the version of the report format, it's increased when a field
+// is changed or removed.
+const Version = 1
+
+// GitHash is the git commit go-ycsb is built from, it's set by
+// -ldflags "-X github.com/pingcap/go-ycsb/pkg/report.GitHash=...".
+var GitHash string
+
+// Report is the result of a run together with what's needed to reproduce it.
+type Report struct {
+	Version    int               `json:"version"`
+	Command    string            `json:"command"`
+	DB         string            `json:"db"`
+	Workload   string            `json:"workload"`
+	Properties map[string]string `json:"properties"`
+	Build      Build             `json:"build"`
+	Host       Host              `json:"host"`
+	StartTime  time.Time         `json:"start_time"`
+	EndTime    time.Time         `json:"end_time"`
+	StopReason string            `json:"stop_reason"`
+	// WarmUp is how long warm-up takes in seconds.
+	WarmUp float64 `json:"warmup_s"`
+	// Operations and Errors are the totals after warm-up, a batch counts as
+	// batch.size operations.
+	Operations int64 `json:"operations"`
+	Errors     int64 `json:"errors"`
+	// Client is the resource usage of the client after warm-up, it's
+	// omitted if the client isn't monitored.
+	Client *Client `json:"client,omitempty"`
+	// Sections are the phases of the run, or one section without name if
+	// the run has no phases.
+	Sections []Section `json:"sections"`
+}
+
+// Build is how go-ycsb is built.
+type Build struct {
+	GitHash   string `json:"git_hash"`
+	GoVersion string `json:"go_version"`
+}
+
+// Host is the machine the client runs on.
+type Host struct {
+	Hostname string `json:"hostname"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	NumCPU   int    `json:"num_cpu"`
+}
+
+// Client is the resource usage of the client process, the heap size and the
+// goroutine count are the peak values.
+type Client struct {
+	CPU             float64 `json:"cpu_percent"`
+	GOMAXPROCS      int     `json:"gomaxprocs"`
+	GCPauseP50      int64   `json:"gc_pause_p50_us"`
+	GCPauseP99      int64   `json:"gc_pause_p99_us"`
+	GCPauseMax      int64   `json:"gc_pause_max_us"`
+	SchedLatencyP99 int64   `json:"sched_latency_p99_us"`
+	HeapBytes       uint64  `json:"heap_bytes"`
+	Goroutines      int64   `json:"goroutines"`
+	// Bottlenecks are why the client may limit the throughput.
+	Bottlenecks []string `json:"bottlenecks,omitempty"`
+}
+
+func newClient(s *client.ResourceStats) *Client {
+	if s == nil {
+		return nil
+	}
+	return &Client{
+		CPU:             s.CPU,
+		GOMAXPROCS:      s.GOMAXPROCS,
+		GCPauseP50:      s.GCPauseP50.Microseconds(),
+		GCPauseP99:      s.GCPauseP99.Microseconds(),
+		GCPauseMax:      s.GCPauseMax.Microseconds(),
+		SchedLatencyP99: s.SchedLatencyP99.Microseconds(),
+		HeapBytes:       s.HeapBytes,
+		Goroutines:      s.Goroutines,
+		Bottlenecks:     s.Bottlenecks(),
+	}
+}
+
+// Section is a measured part of the run.
+type Section struct {
+	Name       string      `json:"name"`
+	StartTime  time.Time   `json:"start_time"`
+	EndTime    time.Time   `json:"end_time"`
+	Operations []Operation `json:"operations"`
+}
+
+// Operation is the result of an operation like READ or READ_ERROR, the
+// latencies are in us.
+type Operation struct {
+	Name    string  `json:"name"`
+	Elapsed float64 `json:"elapsed_s"`
+	Count   int64   `json:"count"`
+	OPS     float64 `json:"ops"`
+	Avg     int64   `json:"avg_us"`
+	Min     int64   `json:"min_us"`
+	Max     int64   `json:"max_us"`
+	P99     int64   `json:"p99_us"`
+	P999    int64   `json:"p999_us"`
+	P9999   int64   `json:"p9999_us"`
+	// BytesRead and BytesWritten are the payload of the operations, the
+	// values read, and the keys and the values written.
+	BytesRead    int64 `json:"bytes_read"`
+	BytesWritten int64 `json:"bytes_written"`
+}
+
+// New builds the report of the finished run from the measurement.
+func New(p *properties.Properties, dbName string, c *client.Client, start time.Time, end time.Time) *Report {
+	r := &Report{
+		Version:    Version,
+		Command:    p.GetString(prop.Command, ""),
+		DB:         dbName,
+		Workload:   p.GetString(prop.Workload, "core"),
+		Properties: p.Map(),
+		Build:      build(),
+		Host:       host(),
+		StartTime:  start,
+		EndTime:    end,
+		StopReason: c.StopReason(),
+		WarmUp:     c.Stats().WarmUp.Seconds(),
+		Operations: c.Stats().Ops,
+		Errors:     c.Stats().Errors,
+		Client:     newClient(c.Resources()),
+	}
+
+	for _, s := range measurement.Sections() {
+		r.Sections = append(r.Sections, newSection(s))
+	}
+	return r
+}
+
+func newSection(s measurement.Section) Section {
+	sec := Section{Name: s.Name, StartTime: s.Start, EndTime: s.End, Operations: []Operation{}}
+
+	names := make([]string, 0, len(s.Info))
+	for name := range s.Info {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		info := s.Info[name]
+		op := Operation{Name: name}
+		op.Elapsed, _ = info[measurement.ELAPSED].(float64)
+		op.Count, _ = info[measurement.COUNT].(int64)
+		op.OPS, _ = info[measurement.QPS].(float64)
+		op.Avg, _ = info[measurement.AVG].(int64)
+		op.Min, _ = info[measurement.MIN].(int64)
+		op.Max, _ = info[measurement.MAX].(int64)
+		op.P99, _ = info[measurement.PER99TH].(int64)
+		op.P999, _ = info[measurement.PER999TH].(int64)
+		op.P9999, _ = info[measurement.PER9999TH].(int64)
+		op.BytesRead, _ = info[measurement.BYTESREAD].(int64)
+		op.BytesWritten, _ = info[measurement.BYTESWRITTEN].(int64)
+		sec.Operations = append(sec.Operations, op)
+	}
+	return sec
+}
+
+func build() Build {
+	b := Build{GitHash: GitHash, GoVersion: runtime.Version()}
+	if b.GitHash != "" {
+		return b
+	}
+
+	// fall back to the version control info stamped by go build
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	modified := false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			b.GitHash = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if b.GitHash != "" && modified {
+		b.GitHash += "-dirty"
+	}
+	return b
+}
+
+func host() Host {
+	h := Host{OS: runtime.GOOS, Arch: runtime.GOARCH, NumCPU: runtime.NumCPU()}
+	h.Hostname, _ = os.Hostname()
+	return h
+}
+
+// Write writes the report as indented JSON to the file.
+func (r *Report) Write(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
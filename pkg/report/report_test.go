@@ -0,0 +1,96 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/client"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+func TestReport(t *testing.T) {
+	p := properties.NewProperties()
+	p.Set(prop.Command, "run")
+	p.Set(prop.RecordCount, "100")
+	measurement.InitMeasure(p)
+
+	start := time.Now()
+	measurement.StartSection("LOAD")
+	measurement.Measure("INSERT", start, time.Millisecond)
+	measurement.StartSection("RUN")
+	measurement.Measure("READ", start, 100*time.Microsecond)
+	measurement.Measure("READ", start, 300*time.Microsecond)
+	measurement.MeasurePayload(-1, "READ", 2000, 0)
+	end := time.Now()
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := New(p, "basic", client.NewClient(p, nil, nil), start, end).Write(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the field names are the format, so check them in the raw JSON
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"version", "command", "db", "workload", "properties", "build", "host",
+		"start_time", "end_time", "stop_reason", "warmup_s", "operations", "errors", "sections"} {
+		if _, ok := raw[field]; !ok {
+			t.Errorf("no %s in the report", field)
+		}
+	}
+	if _, ok := raw["client"]; ok {
+		t.Errorf("want no client without monitoring")
+	}
+
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Version != Version || r.Command != "run" || r.DB != "basic" || r.Workload != "core" ||
+		r.Properties[prop.RecordCount] != "100" || r.Host.NumCPU == 0 || r.Build.GoVersion == "" {
+		t.Fatalf("unexpected report %+v", r)
+	}
+	if len(r.Sections) != 2 || r.Sections[0].Name != "LOAD" || r.Sections[1].Name != "RUN" {
+		t.Fatalf("unexpected sections %+v", r.Sections)
+	}
+	ops := r.Sections[1].Operations
+	if len(ops) != 1 || ops[0].Name != "READ" || ops[0].Count != 2 || ops[0].Min != 100 ||
+		ops[0].Max != 300 || ops[0].P99 != 300 || ops[0].BytesRead != 2000 {
+		t.Fatalf("unexpected operations %+v", ops)
+	}
+
+	// the report can be compared
+	res, err := parseReport(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Ops["RUN"]["READ"]; got[MetricCount] != 2 || got[MetricSize] != 1000 {
+		t.Fatalf("unexpected READ metrics %v", got)
+	}
+	if got := res.Ops["LOAD"]["INSERT"][MetricMax]; got != 1000 {
+		t.Fatalf("want INSERT max 1000, but got %v", got)
+	}
+}
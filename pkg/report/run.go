@@ -0,0 +1,329 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	hdrhistogram "github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// rawHeader is the first line of the raw latency files written by the raw
+// measurement.
+const rawHeader = "operation,timestamp_us,latency_us"
+
+// rawPercentiles are the percentiles computed from the raw latency files.
+var rawPercentiles = []float64{50, 90, 95, 99, 99.9, 99.99}
+
+// percentileMetric matches the metrics of the latency at a percentile like
+// "99.9th(us)".
+var percentileMetric = regexp.MustCompile(`^([0-9.]+)th\(us\)$`)
+
+// Point is a point of a chart.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Run is the result of a database running a workload, loaded from the saved
+// outputs, reports or raw latency files of the run.
+type Run struct {
+	DB       string
+	Workload string
+	*Result
+	// Throughput is the OPS over the seconds since the start of every
+	// operation, it's empty if the files have no periodic summary.
+	Throughput map[string][]Point
+}
+
+// RunName returns the database and the workload of the run from the name of
+// the file, like tikv for tikv.log, or tikv and workloada for
+// tikv_workloada.log written by tool/binary/bench.sh.
+func RunName(path string) (db string, workload string) {
+	name := filepath.Base(path)
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		name = name[:i]
+	}
+	seps := strings.SplitN(name, "_", 2)
+	if len(seps) == 2 {
+		return seps[0], seps[1]
+	}
+	return name, ""
+}
+
+// LoadRun loads the run from the files, which are any of the files
+// LoadResult accepts, or the raw latency files which may be gzipped. The
+// raw files of a run, like the rotated ones, are measured together.
+func LoadRun(db string, workload string, paths []string) (*Run, error) {
+	run := &Run{DB: db, Workload: workload, Result: newResult(), Throughput: make(map[string][]Point)}
+	raw := newRawRun()
+	for _, path := range paths {
+		if err := run.load(path, raw); err != nil {
+			return nil, fmt.Errorf("load %s failed: %v", path, err)
+		}
+	}
+	raw.result(run)
+
+	if len(run.Sections) == 0 {
+		return nil, fmt.Errorf("no operation found in %s", strings.Join(paths, ", "))
+	}
+	return run, nil
+}
+
+func (run *Run) load(path string, raw *rawRun) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	br = bufio.NewReader(r)
+	if head, _ := br.Peek(len(rawHeader)); string(head) == rawHeader {
+		return raw.load(br)
+	}
+
+	data, err := io.ReadAll(br)
+	if err != nil {
+		return err
+	}
+	trimmed := bytes.TrimSpace(data)
+	var res *Result
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var rep struct {
+			DB string `json:"db"`
+		}
+		if err = json.Unmarshal(trimmed, &rep); err == nil && rep.DB != "" {
+			run.DB = rep.DB
+		}
+		res, err = parseReport(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("[{\"name\"")), bytes.HasPrefix(trimmed, []byte("[\n")):
+		res, err = parseExport(trimmed)
+	default:
+		var tp map[string][]Point
+		res, tp, err = parseOutput(data)
+		for op, points := range tp {
+			run.Throughput[op] = append(run.Throughput[op], points...)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, section := range res.Sections {
+		for op, metrics := range res.Ops[section] {
+			run.set(section, op, metrics)
+		}
+	}
+	return nil
+}
+
+// Percentiles returns the latencies in us of the operation in the section
+// over the percentiles, the minimum is the 0th and the maximum is the 100th.
+func (run *Run) Percentiles(section string, op string) []Point {
+	metrics := run.Ops[section][op]
+	var points []Point
+	for name, v := range metrics {
+		switch name {
+		case MetricMin:
+			points = append(points, Point{X: 0, Y: v})
+		case MetricMax:
+			points = append(points, Point{X: 100, Y: v})
+		default:
+			m := percentileMetric.FindStringSubmatch(name)
+			if m == nil {
+				continue
+			}
+			if p, err := strconv.ParseFloat(m[1], 64); err == nil {
+				points = append(points, Point{X: p, Y: v})
+			}
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].X < points[j].X })
+	return points
+}
+
+// throughput builds the OPS over time from the periodic summaries, using the
+// OPS of the last interval if the summaries have it.
+type throughput struct {
+	points map[string][]Point
+	last   map[string]*throughputState
+}
+
+type throughputState struct {
+	takes  float64
+	count  float64
+	offset float64
+}
+
+func newThroughput() *throughput {
+	return &throughput{
+		points: make(map[string][]Point),
+		last:   make(map[string]*throughputState),
+	}
+}
+
+func (t *throughput) add(row map[string]string) {
+	op := row["Operation"]
+	takes, err1 := strconv.ParseFloat(row[MetricTakes], 64)
+	count, err2 := strconv.ParseFloat(row[MetricCount], 64)
+	if op == "" || err1 != nil || err2 != nil {
+		return
+	}
+
+	s, ok := t.last[op]
+	if !ok {
+		s = new(throughputState)
+		t.last[op] = s
+	}
+	if takes < s.takes {
+		// a new phase starts measuring from 0 again
+		s.offset += s.takes
+		s.takes, s.count = 0, 0
+	}
+
+	ops, err := strconv.ParseFloat(row["Intv OPS"], 64)
+	if err != nil {
+		if takes <= s.takes {
+			return
+		}
+		ops = (count - s.count) / (takes - s.takes)
+	}
+	s.takes, s.count = takes, count
+	t.points[op] = append(t.points[op], Point{X: s.offset + takes, Y: ops})
+}
+
+// rawRun measures the samples of the raw latency files.
+type rawRun struct {
+	ops map[string]*rawOp
+	// first and last are the start times of the first and the last samples
+	// in us.
+	first int64
+	last  int64
+}
+
+type rawOp struct {
+	hist      *hdrhistogram.Histogram
+	perSecond map[int64]int64
+}
+
+func newRawRun() *rawRun {
+	return &rawRun{ops: make(map[string]*rawOp), first: math.MaxInt64}
+}
+
+func (raw *rawRun) load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == rawHeader || line == "" {
+			continue
+		}
+
+		seps := strings.Split(line, ",")
+		if len(seps) != 3 {
+			return fmt.Errorf("bad raw sample %q", line)
+		}
+		ts, err := strconv.ParseInt(seps[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("bad raw sample %q: %v", line, err)
+		}
+		lan, err := strconv.ParseInt(seps[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("bad raw sample %q: %v", line, err)
+		}
+		raw.add(seps[0], ts, lan)
+	}
+	return scanner.Err()
+}
+
+func (raw *rawRun) add(op string, ts int64, lan int64) {
+	o, ok := raw.ops[op]
+	if !ok {
+		o = &rawOp{
+			hist:      hdrhistogram.New(1, 24*60*60*1000*1000, 3),
+			perSecond: make(map[int64]int64),
+		}
+		raw.ops[op] = o
+	}
+	o.hist.RecordValue(lan)
+	o.perSecond[ts/1e6]++
+	if ts < raw.first {
+		raw.first = ts
+	}
+	if ts > raw.last {
+		raw.last = ts
+	}
+}
+
+// result adds the summary and the throughput over time of the samples to
+// the run.
+func (raw *rawRun) result(run *Run) {
+	if len(raw.ops) == 0 {
+		return
+	}
+
+	elapsed := float64(raw.last-raw.first) / 1e6
+	firstSecond := raw.first / 1e6
+	for op, o := range raw.ops {
+		h := o.hist
+		metrics := map[string]float64{
+			MetricTakes: elapsed,
+			MetricCount: float64(h.TotalCount()),
+			MetricAvg:   math.Round(h.Mean()),
+			MetricMin:   float64(h.Min()),
+			MetricMax:   float64(h.Max()),
+		}
+		if elapsed > 0 {
+			metrics[MetricOPS] = float64(h.TotalCount()) / elapsed
+		}
+		for _, p := range rawPercentiles {
+			metrics[strconv.FormatFloat(p, 'f', -1, 64)+"th(us)"] = float64(h.ValueAtQuantile(p))
+		}
+		run.set("", op, metrics)
+
+		seconds := make([]int64, 0, len(o.perSecond))
+		for s := range o.perSecond {
+			seconds = append(seconds, s)
+		}
+		sort.Slice(seconds, func(i, j int) bool { return seconds[i] < seconds[j] })
+		points := make([]Point, 0, len(seconds))
+		for _, s := range seconds {
+			points = append(points, Point{X: float64(s - firstSecond + 1), Y: float64(o.perSecond[s])})
+		}
+		run.Throughput[op] = points
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadRawRun(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		rawHeader + "\nREAD,1000000,10\nREAD,1500000,20\nUPDATE,1600000,30\n",
+		// a rotated file
+		rawHeader + "\nREAD,2000000,40\nREAD,3000000,50\n",
+	}
+	var paths []string
+	for i, data := range files {
+		path := filepath.Join(dir, []string{"raw.csv", "raw.1.csv"}[i])
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	db, workload := RunName(paths[1])
+	if db != "raw" || workload != "" {
+		t.Fatalf("want raw run, but got %s %s", db, workload)
+	}
+	run, err := LoadRun(db, workload, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read := run.Ops[""]["READ"]
+	if read[MetricCount] != 4 || read[MetricOPS] != 2 || read[MetricMax] != 50 {
+		t.Fatalf("bad READ summary %v", read)
+	}
+	wantTp := []Point{{X: 1, Y: 2}, {X: 2, Y: 1}, {X: 3, Y: 1}}
+	if !reflect.DeepEqual(run.Throughput["READ"], wantTp) {
+		t.Fatalf("want throughput %v, but got %v", wantTp, run.Throughput["READ"])
+	}
+	pts := run.Percentiles("", "UPDATE")
+	if len(pts) != len(rawPercentiles)+2 || pts[0] != (Point{X: 0, Y: 30}) || pts[len(pts)-1] != (Point{X: 100, Y: 30}) {
+		t.Fatalf("bad UPDATE percentiles %v", pts)
+	}
+}
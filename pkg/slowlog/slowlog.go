@@ -0,0 +1,190 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package slowlog logs the operations slower than a threshold, so the hot
+// keys or the pathological scans behind the tail latency can be found.
+package slowlog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// maxKeys is the maximum number of the keys of a batch operation logged.
+const maxKeys = 8
+
+const timeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// Entry is a slow operation.
+type Entry struct {
+	Start    time.Time
+	ThreadID int
+	Op       string
+	Table    string
+	// Keys are the keys of the operation, or the start key of a scan.
+	Keys []string
+	// Fields are the fields read or written, nil means all fields.
+	Fields    []string
+	BatchSize int
+	Latency   time.Duration
+	Err       error
+}
+
+func (e *Entry) format(b *strings.Builder) {
+	b.WriteString(e.Start.Format(timeFormat))
+	fmt.Fprintf(b, " thread=%d op=%s table=%s", e.ThreadID, e.Op, e.Table)
+
+	b.WriteString(" key=")
+	if len(e.Keys) > maxKeys {
+		b.WriteString(strings.Join(e.Keys[:maxKeys], ","))
+		b.WriteString(",...")
+	} else {
+		b.WriteString(strings.Join(e.Keys, ","))
+	}
+
+	b.WriteString(" fields=")
+	if e.Fields == nil {
+		b.WriteString("*")
+	} else {
+		b.WriteString(strings.Join(e.Fields, ","))
+	}
+
+	fmt.Fprintf(b, " batch=%d latency_us=%d", e.BatchSize, e.Latency.Microseconds())
+	if e.Err != nil {
+		b.WriteString(" error=")
+		b.WriteString(strconv.Quote(e.Err.Error()))
+	}
+	b.WriteByte('\n')
+}
+
+// logger writes at most rate entries per second, the entries over the limit
+// are counted and skipped.
+type logger struct {
+	threshold time.Duration
+	rate      int64
+
+	mu sync.Mutex
+	w  io.WriteCloser
+	// the entries logged and skipped in the current second
+	second  time.Time
+	logged  int64
+	skipped int64
+	// total number of the skipped entries
+	totalSkipped int64
+}
+
+var globalLogger *logger
+
+// Init starts the slow log if slowlog.threshold is set.
+func Init(p *properties.Properties) {
+	threshold := p.GetInt64(prop.SlowLogThreshold, 0)
+	if threshold <= 0 {
+		return
+	}
+
+	rate := p.GetInt64(prop.SlowLogRateLimit, prop.SlowLogRateLimitDefault)
+	if rate <= 0 {
+		util.Fatalf("slow log rate limit must be positive, but got %d", rate)
+	}
+
+	path := p.GetString(prop.SlowLogFile, prop.SlowLogFileDefault)
+	f, err := os.Create(path)
+	if err != nil {
+		util.Fatalf("create slow log %s failed %v", path, err)
+	}
+
+	globalLogger = &logger{
+		threshold: time.Duration(threshold) * time.Microsecond,
+		rate:      rate,
+		w:         f,
+	}
+}
+
+// IsSlow returns whether the latency is over the threshold, it's always false
+// if the slow log is off.
+func IsSlow(lan time.Duration) bool {
+	l := globalLogger
+	return l != nil && lan >= l.threshold
+}
+
+// Log logs the slow operation unless the rate limit is reached.
+func Log(e *Entry) {
+	l := globalLogger
+	if l == nil {
+		return
+	}
+
+	now := time.Now()
+	var b strings.Builder
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.w == nil {
+		return
+	}
+
+	if now.Sub(l.second) >= time.Second {
+		l.writeSkipped()
+		l.second = now
+		l.logged = 0
+	}
+	if l.logged >= l.rate {
+		l.skipped++
+		return
+	}
+	l.logged++
+
+	e.format(&b)
+	// the slow log is best effort, it doesn't fail the benchmark
+	io.WriteString(l.w, b.String())
+}
+
+func (l *logger) writeSkipped() {
+	if l.skipped == 0 {
+		return
+	}
+	fmt.Fprintf(l.w, "%s skipped %d slow operations over the rate limit\n", l.second.Format(timeFormat), l.skipped)
+	l.totalSkipped += l.skipped
+	l.skipped = 0
+}
+
+// Close closes the slow log.
+func Close() {
+	l := globalLogger
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.w == nil {
+		return
+	}
+	l.writeSkipped()
+	if err := l.w.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "slowlog: close failed %v\n", err)
+	}
+	l.w = nil
+	if l.totalSkipped > 0 {
+		fmt.Fprintf(os.Stderr, "slowlog: %d slow operations are skipped over the rate limit\n", l.totalSkipped)
+	}
+}
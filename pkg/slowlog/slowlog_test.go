@@ -0,0 +1,59 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slowlog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+func TestSlowLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slow.log")
+	p := properties.NewProperties()
+	p.Set(prop.SlowLogThreshold, "1000")
+	p.Set(prop.SlowLogFile, path)
+	p.Set(prop.SlowLogRateLimit, "2")
+	Init(p)
+	defer func() { globalLogger = nil }()
+
+	if IsSlow(999*time.Microsecond) || !IsSlow(time.Millisecond) {
+		t.Fatal("bad threshold")
+	}
+	start := time.Date(2018, 1, 2, 3, 4, 5, 6000, time.UTC)
+	Log(&Entry{Start: start, ThreadID: 3, Op: "READ", Table: "t", Keys: []string{"k1"}, BatchSize: 1, Latency: 2 * time.Millisecond})
+	Log(&Entry{Start: start, ThreadID: 4, Op: "BATCH_UPDATE", Table: "t", Keys: []string{"k1", "k2"}, Fields: []string{"f0"}, BatchSize: 2, Latency: 3 * time.Millisecond, Err: errors.New("timeout")})
+	// over the rate limit
+	Log(&Entry{Start: start, ThreadID: 5, Op: "SCAN", Table: "t", Keys: []string{"k1"}, BatchSize: 1, Latency: 4 * time.Millisecond})
+	Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	want := []string{
+		"2018-01-02T03:04:05.000006Z thread=3 op=READ table=t key=k1 fields=* batch=1 latency_us=2000",
+		`2018-01-02T03:04:05.000006Z thread=4 op=BATCH_UPDATE table=t key=k1,k2 fields=f0 batch=2 latency_us=3000 error="timeout"`,
+	}
+	if len(lines) != 3 || lines[0] != want[0] || lines[1] != want[1] || !strings.HasSuffix(lines[2], "skipped 1 slow operations over the rate limit") {
+		t.Fatalf("bad slow log %q", lines)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// exporter sends the encoded spans out, it's only used by the goroutine of
+// the tracer.
+type exporter interface {
+	export(data []byte) error
+	close() error
+}
+
+// The OTLP JSON encoding of ExportTraceServiceRequest, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	IntValue    *string         `json:"intValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpValue `json:"values"`
+}
+
+type otlpStatus struct {
+	Message string `json:"message,omitempty"`
+	Code    int    `json:"code"`
+}
+
+const (
+	spanKindClient = 3
+	// the status of the succeeded operations is left unset
+	statusCodeError = 2
+)
+
+func formatNanos(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func stringValue(s string) otlpValue {
+	return otlpValue{StringValue: &s}
+}
+
+func encodeAttributes(attrs []attribute) []otlpKeyValue {
+	kvs := make([]otlpKeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		kv := otlpKeyValue{Key: a.key}
+		switch {
+		case a.hasNum:
+			n := strconv.FormatInt(a.num, 10)
+			kv.Value.IntValue = &n
+		case a.strs != nil:
+			arr := &otlpArrayValue{Values: make([]otlpValue, 0, len(a.strs))}
+			for _, s := range a.strs {
+				arr.Values = append(arr.Values, stringValue(s))
+			}
+			kv.Value.ArrayValue = arr
+		default:
+			kv.Value = stringValue(a.str)
+		}
+		kvs = append(kvs, kv)
+	}
+	return kvs
+}
+
+// encodeSpans encodes the spans as an OTLP JSON request.
+func encodeSpans(resource []attribute, spans []*Span) []byte {
+	scope := otlpScopeSpans{Scope: otlpScope{Name: "github.com/pingcap/go-ycsb"}}
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              spanKindClient,
+			StartTimeUnixNano: formatNanos(s.start),
+			EndTimeUnixNano:   formatNanos(s.end),
+			Attributes:        encodeAttributes(s.attrs),
+		}
+		if s.parentID != [8]byte{} {
+			span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+		if s.errMsg != "" {
+			span.Status = otlpStatus{Code: statusCodeError, Message: s.errMsg}
+		}
+		scope.Spans = append(scope.Spans, span)
+	}
+
+	req := otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: encodeAttributes(resource)},
+		ScopeSpans: []otlpScopeSpans{scope},
+	}}}
+	// the request only has strings and numbers, it can't fail
+	data, _ := json.Marshal(req)
+	return data
+}
+
+// fileExporter writes every request as a line of the file, like the file
+// exporter of the OpenTelemetry Collector, so the file can be replayed to a
+// collector later.
+type fileExporter struct {
+	f *os.File
+	w *bufio.Writer
+}
+
+func newFileExporter(path string) (*fileExporter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &fileExporter{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+func (e *fileExporter) export(data []byte) error {
+	if _, err := e.w.Write(data); err != nil {
+		return err
+	}
+	return e.w.WriteByte('\n')
+}
+
+func (e *fileExporter) close() error {
+	if err := e.w.Flush(); err != nil {
+		e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
+
+// otlpExporter posts the requests to the OTLP/HTTP endpoint of a collector.
+type otlpExporter struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newOTLPExporter(endpoint string) *otlpExporter {
+	return &otlpExporter{endpoint: endpoint, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (e *otlpExporter) export(data []byte) error {
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector responds %s", resp.Status)
+	}
+	return nil
+}
+
+func (e *otlpExporter) close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
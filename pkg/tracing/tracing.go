@@ -0,0 +1,277 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing traces the operations of the benchmark as OpenTelemetry
+// spans, which are exported in the OTLP JSON encoding to a collector or to a
+// local file.
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+const (
+	// queueSize is the number of spans queued for the exporter, the spans
+	// are dropped when the exporter falls that far behind.
+	queueSize = 8192
+	// batchSize is the maximum number of spans exported at once.
+	batchSize = 512
+	// flushInterval is how often the queued spans are exported.
+	flushInterval = time.Second
+)
+
+// Span is a traced operation. A nil Span is an operation not traced, all
+// its methods do nothing.
+type Span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	start    time.Time
+	end      time.Time
+	attrs    []attribute
+	errMsg   string
+}
+
+type attribute struct {
+	key    string
+	str    string
+	strs   []string
+	num    int64
+	hasNum bool
+}
+
+type spanKey struct{}
+
+// tracer samples the spans and sends them to the exporter.
+type tracer struct {
+	probability float64
+	resource    []attribute
+
+	exporter exporter
+	// mu guards ch from being closed while a span is sent.
+	mu      sync.RWMutex
+	closed  bool
+	ch      chan *Span
+	done    chan struct{}
+	dropped int64
+	// failed is the number of the failed exports, only the first error is
+	// printed.
+	failed int64
+}
+
+var globalTracer *tracer
+
+// Init starts tracing if tracing.exporter is set, the spans are labeled with
+// the db, the command and the label property.
+func Init(p *properties.Properties, dbName string) {
+	name := p.GetString(prop.TracingExporter, "")
+	if name == "" {
+		return
+	}
+
+	var (
+		e   exporter
+		err error
+	)
+	switch name {
+	case "otlp":
+		e = newOTLPExporter(p.GetString(prop.TracingOTLPEndpoint, prop.TracingOTLPEndpointDefault))
+	case "file":
+		e, err = newFileExporter(p.GetString(prop.TracingFile, prop.TracingFileDefault))
+	default:
+		util.Fatalf("unsupported tracing exporter %s", name)
+	}
+	if err != nil {
+		util.Fatalf("create tracing exporter %s failed %v", name, err)
+	}
+
+	probability := p.GetFloat64(prop.TracingSamplerProbability, prop.TracingSamplerProbabilityDefault)
+	if probability < 0 || probability > 1 {
+		util.Fatalf("tracing sampler probability must be in [0, 1], but got %v", probability)
+	}
+
+	t := &tracer{
+		probability: probability,
+		resource: []attribute{
+			{key: "service.name", str: "go-ycsb"},
+			{key: "db.system", str: dbName},
+			{key: "ycsb.command", str: p.GetString(prop.Command, "")},
+			{key: "ycsb.label", str: p.GetString(prop.Label, "")},
+		},
+		exporter: e,
+		ch:       make(chan *Span, queueSize),
+		done:     make(chan struct{}),
+	}
+	go t.run()
+	globalTracer = t
+}
+
+// Close exports the spans left and stops tracing.
+func Close() {
+	t := globalTracer
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.closed = true
+	close(t.ch)
+	t.mu.Unlock()
+
+	<-t.done
+	if dropped := atomic.LoadInt64(&t.dropped); dropped > 0 {
+		fmt.Fprintf(os.Stderr, "tracing: %d spans are dropped as the exporter falls behind\n", dropped)
+	}
+	if t.failed > 0 {
+		fmt.Fprintf(os.Stderr, "tracing: %d exports failed\n", t.failed)
+	}
+}
+
+// Start starts the span of the operation on the table if tracing is on and
+// the operation is sampled, otherwise it returns the context and a nil span.
+// The span is a child of the span in the context if any, and every child of
+// a sampled span is sampled.
+func Start(ctx context.Context, op string, table string) (context.Context, *Span) {
+	t := globalTracer
+	if t == nil {
+		return ctx, nil
+	}
+
+	s := &Span{name: op, start: time.Now()}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok {
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		if t.probability < 1 && rand.Float64() >= t.probability {
+			return ctx, nil
+		}
+		binary.BigEndian.PutUint64(s.traceID[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(s.traceID[8:], rand.Uint64())
+	}
+	binary.BigEndian.PutUint64(s.spanID[:], rand.Uint64())
+
+	s.attrs = append(s.attrs, attribute{key: "ycsb.op", str: op}, attribute{key: "ycsb.table", str: table})
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// SetKey sets the key of the operation.
+func (s *Span) SetKey(key string) {
+	if s == nil {
+		return
+	}
+	s.attrs = append(s.attrs, attribute{key: "ycsb.key", str: key})
+}
+
+// SetKeys sets the keys and the size of the batch operation.
+func (s *Span) SetKeys(keys []string) {
+	if s == nil {
+		return
+	}
+	// the spans are exported later, the workload may reuse the slice
+	s.attrs = append(s.attrs,
+		attribute{key: "ycsb.keys", strs: append([]string(nil), keys...)},
+		attribute{key: "ycsb.batch_size", num: int64(len(keys)), hasNum: true})
+}
+
+// End ends the span, it's failed if err isn't nil and class is the kind of
+// the error like TIMEOUT.
+func (s *Span) End(class string, err error) {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	if err != nil {
+		s.errMsg = err.Error()
+		s.attrs = append(s.attrs, attribute{key: "error.type", str: class})
+	}
+
+	t := globalTracer
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.closed {
+		return
+	}
+	select {
+	case t.ch <- s:
+	default:
+		atomic.AddInt64(&t.dropped, 1)
+	}
+}
+
+// Traceparent returns the W3C traceparent header of the span in the context,
+// or "" if the operation isn't traced, so a database binding can pass the
+// trace context to the server.
+func Traceparent(ctx context.Context) string {
+	s, ok := ctx.Value(spanKey{}).(*Span)
+	if !ok || s == nil {
+		return ""
+	}
+	return "00-" + hex.EncodeToString(s.traceID[:]) + "-" + hex.EncodeToString(s.spanID[:]) + "-01"
+}
+
+func (t *tracer) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := t.exporter.export(encodeSpans(t.resource, batch)); err != nil {
+			if t.failed == 0 {
+				fmt.Fprintf(os.Stderr, "tracing: export %d spans failed %v\n", len(batch), err)
+			}
+			t.failed++
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case s, ok := <-t.ch:
+			if !ok {
+				flush()
+				if err := t.exporter.close(); err != nil {
+					fmt.Fprintf(os.Stderr, "tracing: close exporter failed %v\n", err)
+				}
+				return
+			}
+			batch = append(batch, s)
+			if len(batch) == batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.json")
+	p := properties.NewProperties()
+	p.Set(prop.TracingExporter, "file")
+	p.Set(prop.TracingFile, path)
+	p.Set(prop.TracingSamplerProbability, "1")
+	Init(p, "basic")
+	defer func() { globalTracer = nil }()
+
+	ctx, parent := Start(context.Background(), "BATCH_READ", "usertable")
+	parent.SetKeys([]string{"k1", "k2"})
+	childCtx, child := Start(ctx, "READ", "usertable")
+	child.SetKey("k1")
+	child.End("TIMEOUT", errors.New("timeout"))
+	parent.End("", nil)
+	Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req otlpRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		t.Fatal(err)
+	}
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("want 2 spans, got %d", len(spans))
+	}
+	c, s := spans[0], spans[1]
+	if c.TraceID != s.TraceID || c.ParentSpanID != s.SpanID || s.ParentSpanID != "" {
+		t.Fatalf("child %+v isn't in the trace of %+v", c, s)
+	}
+	if tp := Traceparent(childCtx); tp != "00-"+c.TraceID+"-"+c.SpanID+"-01" {
+		t.Fatalf("bad traceparent %q of %+v", tp, c)
+	}
+	if tp := Traceparent(context.Background()); tp != "" {
+		t.Fatalf("want no traceparent without a span, but got %q", tp)
+	}
+	if c.Status.Code != statusCodeError || c.Status.Message != "timeout" || s.Status.Code != 0 {
+		t.Fatalf("bad status %+v %+v", c.Status, s.Status)
+	}
+	if kv := s.Attributes[3]; kv.Key != "ycsb.batch_size" || *kv.Value.IntValue != "2" {
+		t.Fatalf("bad batch size %+v", kv)
+	}
+}
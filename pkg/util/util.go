@@ -71,3 +71,15 @@ func (b *BufPool) Get() []byte {
 func (b *BufPool) Put(buf []byte) {
 	b.p.Put(buf)
 }
+
+// RowKeyRange returns the bounds of the row keys like "table:key" in the
+// range [startKey, endKey) of the table. An empty endKey means the end of the
+// table, which is "table;" as ';' follows ':'.
+func RowKeyRange(table string, startKey string, endKey string) (string, string) {
+	rowStartKey := table + ":" + startKey
+	rowEndKey := table + ";"
+	if endKey != "" {
+		rowEndKey = table + ":" + endKey
+	}
+	return rowStartKey, rowEndKey
+}
@@ -0,0 +1,30 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import "testing"
+
+func TestRowKeyRange(t *testing.T) {
+	start, end := RowKeyRange("usertable", "user1", "")
+	if start != "usertable:user1" || end != "usertable;" {
+		t.Fatalf("unexpected range [%s, %s)", start, end)
+	}
+	// every key of the table is before the end of the table
+	if key := "usertable:" + string([]byte{0xff}); key >= end {
+		t.Fatalf("%q isn't before %q", key, end)
+	}
+	if _, end = RowKeyRange("usertable", "", "user5"); end != "usertable:user5" {
+		t.Fatalf("unexpected end %s", end)
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/generator"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+const closedEconomyStateKey = contextKey("closedeconomy")
+
+// balanceField is the field holding the balance of an account.
+const balanceField = "field0"
+
+type closedEconomyState struct {
+	r        *rand.Rand
+	threadID int
+}
+
+// closedEconomy is the closed economy workload of YCSB+T. Every record is an
+// account, and every transaction moves money between some accounts, so the
+// total balance never changes if the transactions are atomic and isolated,
+// which is checked by Validate after the run.
+type closedEconomy struct {
+	p              *properties.Properties
+	table          string
+	recordCount    int64
+	initialBalance int64
+	keysPerTxn     int
+	maxTransfer    int64
+	orderedInserts bool
+	zeroPadding    int64
+	// the accounts are the insertCount keys from insertStart.
+	insertStart int64
+	insertCount int64
+
+	keySequence ycsb.Generator
+	keyChooser  ycsb.Generator
+}
+
+// Close implements the Workload Close interface.
+func (c *closedEconomy) Close() error {
+	return nil
+}
+
+// InitThread implements the Workload InitThread interface.
+func (c *closedEconomy) InitThread(ctx context.Context, threadID int, _ int) context.Context {
+	state := &closedEconomyState{
+		r:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		threadID: threadID,
+	}
+	return context.WithValue(ctx, closedEconomyStateKey, state)
+}
+
+// CleanupThread implements the Workload CleanupThread interface.
+func (c *closedEconomy) CleanupThread(_ context.Context) {
+}
+
+// Load implements the Workload Load interface.
+func (c *closedEconomy) Load(ctx context.Context, db ycsb.DB, totalCount int64) error {
+	return nil
+}
+
+func (c *closedEconomy) buildKeyName(keyNum int64) string {
+	if !c.orderedInserts {
+		keyNum = util.Hash64(keyNum)
+	}
+
+	prefix := c.p.GetString(prop.KeyPrefix, prop.KeyPrefixDefault)
+	return fmt.Sprintf("%s%0[3]*[2]d", prefix, keyNum, c.zeroPadding)
+}
+
+func (c *closedEconomy) initialValues() map[string][]byte {
+	return map[string][]byte{balanceField: []byte(strconv.FormatInt(c.initialBalance, 10))}
+}
+
+// DoInsert implements the Workload DoInsert interface, it opens an account
+// with the initial balance.
+func (c *closedEconomy) DoInsert(ctx context.Context, db ycsb.DB) error {
+	state := ctx.Value(closedEconomyStateKey).(*closedEconomyState)
+	keyNum := c.keySequence.Next(state.r)
+	return db.Insert(ctx, c.table, c.buildKeyName(keyNum), c.initialValues())
+}
+
+// DoBatchInsert implements the Workload DoBatchInsert interface.
+func (c *closedEconomy) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) error {
+	batchDB, ok := db.(ycsb.BatchDB)
+	if !ok {
+		return fmt.Errorf("the %T doesn't implement the BatchDB interface", db)
+	}
+	state := ctx.Value(closedEconomyStateKey).(*closedEconomyState)
+	keys := make([]string, batchSize)
+	values := make([]map[string][]byte, batchSize)
+	for i := 0; i < batchSize; i++ {
+		keys[i] = c.buildKeyName(c.keySequence.Next(state.r))
+		values[i] = c.initialValues()
+	}
+	return batchDB.BatchInsert(ctx, c.table, keys, values)
+}
+
+// DoTransaction implements the Workload DoTransaction interface, it moves
+// money from an account to the others in a transaction.
+func (c *closedEconomy) DoTransaction(ctx context.Context, db ycsb.DB) error {
+	txnDB, ok := db.(ycsb.TxnDB)
+	if !ok {
+		return fmt.Errorf("the %T doesn't implement the TxnDB interface", db)
+	}
+	state := ctx.Value(closedEconomyStateKey).(*closedEconomyState)
+
+	// the keys are accessed in order to avoid deadlocks in the databases which
+	// lock the records read.
+	keys := c.chooseKeys(state.r)
+	sort.Strings(keys)
+
+	start := time.Now()
+	txn, err := txnDB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	if err = c.transfer(ctx, state.r, txn, keys); err != nil {
+		txn.Abort(ctx)
+		return err
+	}
+	if err = txn.Commit(ctx); err != nil {
+		return err
+	}
+	measurement.MeasureThread(state.threadID, "TXN", start, time.Now().Sub(start))
+	return nil
+}
+
+// DoBatchTransaction implements the Workload DoBatchTransaction interface, it
+// does batchSize transactions.
+func (c *closedEconomy) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB) error {
+	for i := 0; i < batchSize; i++ {
+		if err := c.DoTransaction(ctx, db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chooseKeys returns keysPerTxn distinct accounts.
+func (c *closedEconomy) chooseKeys(r *rand.Rand) []string {
+	keyNums := make(map[int64]struct{}, c.keysPerTxn)
+	keys := make([]string, 0, c.keysPerTxn)
+	for len(keys) < c.keysPerTxn {
+		keyNum := c.keyChooser.Next(r)
+		if _, ok := keyNums[keyNum]; ok {
+			continue
+		}
+		keyNums[keyNum] = struct{}{}
+		keys = append(keys, c.buildKeyName(keyNum))
+	}
+	return keys
+}
+
+// transfer reads the balances of the accounts, and moves a random amount
+// from one of them to each of the others.
+func (c *closedEconomy) transfer(ctx context.Context, r *rand.Rand, txn ycsb.Txn, keys []string) error {
+	balances := make([]int64, len(keys))
+	for i, key := range keys {
+		values, err := txn.Read(ctx, c.table, key, []string{balanceField})
+		if err != nil {
+			return err
+		}
+		if balances[i], err = parseBalance(values); err != nil {
+			return fmt.Errorf("read %s failed %v", key, err)
+		}
+	}
+
+	from := r.Intn(len(keys))
+	for i := range keys {
+		if i == from || balances[from] <= 0 {
+			continue
+		}
+		amount := c.maxTransfer
+		if amount > balances[from] {
+			amount = balances[from]
+		}
+		amount = r.Int63n(amount) + 1
+		balances[from] -= amount
+		balances[i] += amount
+	}
+
+	for i, key := range keys {
+		values := map[string][]byte{balanceField: []byte(strconv.FormatInt(balances[i], 10))}
+		if err := txn.Write(ctx, c.table, key, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseBalance(values map[string][]byte) (int64, error) {
+	v, ok := values[balanceField]
+	if !ok {
+		return 0, fmt.Errorf("no %s", balanceField)
+	}
+	return strconv.ParseInt(string(v), 10, 64)
+}
+
+// Validate implements the ValidatedWorkload Validate interface, it checks
+// that the total balance of all accounts is the same as it's loaded and no
+// balance is negative.
+func (c *closedEconomy) Validate(ctx context.Context, db ycsb.DB) error {
+	var total, negative int64
+	for keyNum := c.insertStart; keyNum < c.insertStart+c.insertCount; keyNum++ {
+		key := c.buildKeyName(keyNum)
+		values, err := db.Read(ctx, c.table, key, []string{balanceField})
+		if err != nil {
+			return fmt.Errorf("read %s failed %v", key, err)
+		}
+		balance, err := parseBalance(values)
+		if err != nil {
+			return fmt.Errorf("read %s failed %v", key, err)
+		}
+		if balance < 0 {
+			negative++
+		}
+		total += balance
+	}
+
+	expected := c.insertCount * c.initialBalance
+	fmt.Printf("Validation: total balance of %d accounts is %d, expected %d, %d negative balances\n",
+		c.insertCount, total, expected, negative)
+	if total != expected {
+		return fmt.Errorf("total balance %d isn't conserved, expected %d", total, expected)
+	}
+	if negative > 0 {
+		return fmt.Errorf("%d accounts have negative balances", negative)
+	}
+	return nil
+}
+
+type closedEconomyCreator struct{}
+
+// Create implements the WorkloadCreator Create interface.
+func (closedEconomyCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
+	c := &closedEconomy{
+		p:              p,
+		table:          p.GetString(prop.TableName, prop.TableNameDefault),
+		recordCount:    p.GetInt64(prop.RecordCount, prop.RecordCountDefault),
+		initialBalance: p.GetInt64(prop.ClosedEconomyInitialBalance, prop.ClosedEconomyInitialBalanceDefault),
+		keysPerTxn:     p.GetInt(prop.ClosedEconomyKeys, prop.ClosedEconomyKeysDefault),
+		maxTransfer:    p.GetInt64(prop.ClosedEconomyMaxTransfer, prop.ClosedEconomyMaxTransferDefault),
+		orderedInserts: p.GetString(prop.InsertOrder, prop.InsertOrderDefault) != "hashed",
+		zeroPadding:    p.GetInt64(prop.ZeroPadding, prop.ZeroPaddingDefault),
+	}
+	c.insertStart = p.GetInt64(prop.InsertStart, prop.InsertStartDefault)
+	c.insertCount = p.GetInt64(prop.InsertCount, c.recordCount-c.insertStart)
+	if c.insertStart < 0 || c.insertCount <= 0 || c.recordCount < c.insertStart+c.insertCount {
+		return nil, fmt.Errorf("%s %d and %s %d must be a non-empty range within %s %d",
+			prop.InsertStart, c.insertStart, prop.InsertCount, c.insertCount, prop.RecordCount, c.recordCount)
+	}
+	if c.keysPerTxn < 2 || int64(c.keysPerTxn) > c.insertCount {
+		return nil, fmt.Errorf("%s must be in [2, %s], but got %d", prop.ClosedEconomyKeys, prop.InsertCount, c.keysPerTxn)
+	}
+	if c.initialBalance < 0 || c.maxTransfer <= 0 {
+		return nil, fmt.Errorf("%s can't be negative and %s must be positive",
+			prop.ClosedEconomyInitialBalance, prop.ClosedEconomyMaxTransfer)
+	}
+
+	c.keySequence = generator.NewCounter(c.insertStart)
+	last := c.insertStart + c.insertCount - 1
+	switch requestDistrib := p.GetString(prop.RequestDistribution, prop.RequestDistributionDefault); requestDistrib {
+	case "uniform":
+		c.keyChooser = generator.NewUniform(c.insertStart, last)
+	case "zipfian":
+		c.keyChooser = generator.NewScrambledZipfian(c.insertStart, last, generator.ZipfianConstant)
+	default:
+		return nil, fmt.Errorf("unsupported request distribution %s, must be uniform or zipfian", requestDistrib)
+	}
+	return c, nil
+}
+
+func init() {
+	ycsb.RegisterWorkloadCreator("closedeconomy", closedEconomyCreator{})
+}
@@ -0,0 +1,168 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// memDB keeps the records in memory, its transactions run one at a time.
+type memDB struct {
+	ycsb.DB
+
+	txnMu   sync.Mutex
+	mu      sync.Mutex
+	records map[string]map[string][]byte
+}
+
+func newMemDB() *memDB {
+	return &memDB{records: make(map[string]map[string][]byte)}
+}
+
+func (db *memDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	values, ok := db.records[key]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", key)
+	}
+	return values, nil
+}
+
+func (db *memDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.records[key] = values
+	return nil
+}
+
+func (db *memDB) Begin(ctx context.Context) (ycsb.Txn, error) {
+	db.txnMu.Lock()
+	return &memTxn{db: db, writes: make(map[string]map[string][]byte)}, nil
+}
+
+type memTxn struct {
+	db     *memDB
+	writes map[string]map[string][]byte
+}
+
+func (t *memTxn) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	if values, ok := t.writes[key]; ok {
+		return values, nil
+	}
+	return t.db.Read(ctx, table, key, fields)
+}
+
+func (t *memTxn) Write(ctx context.Context, table string, key string, values map[string][]byte) error {
+	t.writes[key] = values
+	return nil
+}
+
+func (t *memTxn) Commit(ctx context.Context) error {
+	for key, values := range t.writes {
+		t.db.Insert(ctx, "", key, values)
+	}
+	t.db.txnMu.Unlock()
+	return nil
+}
+
+func (t *memTxn) Abort(ctx context.Context) error {
+	t.db.txnMu.Unlock()
+	return nil
+}
+
+func newTestClosedEconomy(t *testing.T, props map[string]string) ycsb.ValidatedWorkload {
+	p := properties.NewProperties()
+	for k, v := range props {
+		p.Set(k, v)
+	}
+	w, err := closedEconomyCreator{}.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w.(ycsb.ValidatedWorkload)
+}
+
+func TestClosedEconomy(t *testing.T) {
+	measurement.InitMeasure(properties.NewProperties())
+	w := newTestClosedEconomy(t, map[string]string{
+		prop.RecordCount:       "100",
+		prop.InsertStart:       "50",
+		prop.InsertOrder:       "ordered",
+		prop.ClosedEconomyKeys: "3",
+	})
+	db := newMemDB()
+	ctx := w.InitThread(context.Background(), 0, 1)
+	for i := 0; i < 50; i++ {
+		if err := w.DoInsert(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := db.records["user50"]; !ok || len(db.records) != 50 {
+		t.Fatalf("want 50 accounts from user50, but got %d", len(db.records))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		ctx := w.InitThread(context.Background(), i, 8)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if err := w.DoTransaction(ctx, db); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := w.Validate(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	if count := measurement.Info()["TXN"][measurement.COUNT]; count != int64(1600) {
+		t.Fatalf("want 1600 transactions, but got %v", count)
+	}
+
+	// the money isn't conserved
+	balance := mustBalance(t, db, "user60")
+	db.records["user60"] = map[string][]byte{balanceField: []byte("0")}
+	if err := w.Validate(context.Background(), db); err == nil || !strings.Contains(err.Error(), "isn't conserved") {
+		t.Fatalf("want the total balance not conserved, but got %v", err)
+	}
+
+	// or a balance is negative
+	db.records["user60"] = map[string][]byte{balanceField: []byte("-1")}
+	db.records["user61"] = map[string][]byte{balanceField: []byte(fmt.Sprint(balance + mustBalance(t, db, "user61") + 1))}
+	if err := w.Validate(context.Background(), db); err == nil || !strings.Contains(err.Error(), "negative") {
+		t.Fatalf("want a negative balance, but got %v", err)
+	}
+}
+
+func mustBalance(t *testing.T, db *memDB, key string) int64 {
+	b, err := parseBalance(db.records[key])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -40,6 +40,7 @@ type coreState struct {
 	r *rand.Rand
 	// fieldNames is a copy of core.fieldNames to be goroutine-local
 	fieldNames []string
+	threadID   int
 }
 
 type operationType int64
@@ -50,8 +51,14 @@ const (
 	insert
 	scan
 	readModifyWrite
+	deleteOp
 )
 
+// deletedKeyRetries is how many times a key is chosen again if it's deleted.
+const deletedKeyRetries = 100
+
+var errNoKeyToDelete = fmt.Errorf("no key to delete after %d tries, almost all of the keys are deleted", deletedKeyRetries)
+
 // Core is the core benchmark scenario. Represents a set of clients doing simple CRUD operations.
 type core struct {
 	p *properties.Properties
@@ -71,11 +78,17 @@ type core struct {
 	fieldChooser                 ycsb.Generator
 	transactionInsertKeySequence *generator.AcknowledgedCounter
 	scanLength                   ycsb.Generator
+	scanReverseProportion        float64
+	scanEndBounded               bool
 	orderedInserts               bool
 	recordCount                  int64
 	zeroPadding                  int64
 	insertionRetryLimit          int64
 	insertionRetryInterval       int64
+	// deletedKeys are the key numbers deleted by the run, they're only
+	// tracked if there are deletes.
+	trackDeletes bool
+	deletedKeys  *keySet
 
 	valuePool sync.Pool
 }
@@ -108,6 +121,7 @@ func createOperationGenerator(p *properties.Properties) *generator.Discrete {
 	insertProportion := p.GetFloat64(prop.InsertProportion, prop.InsertProportionDefault)
 	scanProportion := p.GetFloat64(prop.ScanProportion, prop.ScanProportionDefault)
 	readModifyWriteProportion := p.GetFloat64(prop.ReadModifyWriteProportion, prop.ReadModifyWriteProportionDefault)
+	deleteProportion := p.GetFloat64(prop.DeleteProportion, prop.DeleteProportionDefault)
 
 	operationChooser := generator.NewDiscrete()
 	if readProportion > 0 {
@@ -130,6 +144,10 @@ func createOperationGenerator(p *properties.Properties) *generator.Discrete {
 		operationChooser.Add(readModifyWriteProportion, int64(readModifyWrite))
 	}
 
+	if deleteProportion > 0 {
+		operationChooser.Add(deleteProportion, int64(deleteOp))
+	}
+
 	return operationChooser
 }
 
@@ -139,13 +157,14 @@ func (c *core) Load(ctx context.Context, db ycsb.DB, totalCount int64) error {
 }
 
 // InitThread implements the Workload InitThread interface.
-func (c *core) InitThread(ctx context.Context, _ int, _ int) context.Context {
+func (c *core) InitThread(ctx context.Context, threadID int, _ int) context.Context {
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	fieldNames := make([]string, len(c.fieldNames))
 	copy(fieldNames, c.fieldNames)
 	state := &coreState{
 		r:          r,
 		fieldNames: fieldNames,
+		threadID:   threadID,
 	}
 	return context.WithValue(ctx, stateKey, state)
 }
@@ -370,6 +389,8 @@ func (c *core) DoTransaction(ctx context.Context, db ycsb.DB) error {
 		return c.doTransactionInsert(ctx, db, state)
 	case scan:
 		return c.doTransactionScan(ctx, db, state)
+	case deleteOp:
+		return c.doTransactionDelete(ctx, db, state)
 	default:
 		return c.doTransactionReadModifyWrite(ctx, db, state)
 	}
@@ -392,6 +413,8 @@ func (c *core) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB
 		return c.doBatchTransactionInsert(ctx, batchSize, batchDB, state)
 	case update:
 		return c.doBatchTransactionUpdate(ctx, batchSize, batchDB, state)
+	case deleteOp:
+		return c.doBatchTransactionDelete(ctx, batchSize, batchDB, state)
 	case scan:
 		panic("The batch mode don't support the scan operation")
 	default:
@@ -399,7 +422,7 @@ func (c *core) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB
 	}
 }
 
-func (c *core) nextKeyNum(state *coreState) int64 {
+func (c *core) chooseKeyNum(state *coreState) int64 {
 	r := state.r
 	keyNum := int64(0)
 	if _, ok := c.keyChooser.(*generator.Exponential); ok {
@@ -413,6 +436,31 @@ func (c *core) nextKeyNum(state *coreState) int64 {
 	return keyNum
 }
 
+// nextKeyNum returns the key number of the next operation, the deleted keys
+// are avoided unless almost all of the keys are deleted.
+func (c *core) nextKeyNum(state *coreState) int64 {
+	keyNum := c.chooseKeyNum(state)
+	if !c.trackDeletes {
+		return keyNum
+	}
+	for i := 0; i < deletedKeyRetries && c.deletedKeys.has(keyNum); i++ {
+		keyNum = c.chooseKeyNum(state)
+	}
+	return keyNum
+}
+
+// nextDeleteKeyNum returns a key number not deleted yet and marks it as
+// deleted, so the other operations avoid it from now on.
+func (c *core) nextDeleteKeyNum(state *coreState) (int64, error) {
+	for i := 0; i < deletedKeyRetries; i++ {
+		keyNum := c.chooseKeyNum(state)
+		if c.deletedKeys.add(keyNum) {
+			return keyNum, nil
+		}
+	}
+	return 0, errNoKeyToDelete
+}
+
 func (c *core) doTransactionRead(ctx context.Context, db ycsb.DB, state *coreState) error {
 	r := state.r
 	keyNum := c.nextKeyNum(state)
@@ -441,7 +489,7 @@ func (c *core) doTransactionRead(ctx context.Context, db ycsb.DB, state *coreSta
 func (c *core) doTransactionReadModifyWrite(ctx context.Context, db ycsb.DB, state *coreState) error {
 	start := time.Now()
 	defer func() {
-		measurement.Measure("READ_MODIFY_WRITE", start, time.Now().Sub(start))
+		measurement.MeasureThread(state.threadID, "READ_MODIFY_WRITE", start, time.Now().Sub(start))
 	}()
 
 	r := state.r
@@ -506,8 +554,36 @@ func (c *core) doTransactionScan(ctx context.Context, db ycsb.DB, state *coreSta
 		fields = state.fieldNames
 	}
 
-	_, err := db.Scan(ctx, c.table, startKeyName, int(scanLen), fields)
+	reverse := c.scanReverseProportion > 0 && r.Float64() < c.scanReverseProportion
+	if !reverse && !c.scanEndBounded {
+		_, err := db.Scan(ctx, c.table, startKeyName, int(scanLen), fields)
+		return err
+	}
+
+	rangeDB, ok := db.(ycsb.RangeScanDB)
+	if !ok {
+		return fmt.Errorf("the %T doesn't implement the RangeScanDB interface", db)
+	}
 
+	// a forward scan goes up from the key, and a reverse scan goes down from
+	// the key, which is the exclusive end of its range so the record of the key
+	// isn't read. The bound is the key scanLen records away.
+	startKey, endKey := startKeyName, ""
+	if reverse {
+		startKey, endKey = "", startKeyName
+	}
+	if c.scanEndBounded {
+		if reverse {
+			boundNum := keyNum - scanLen
+			if boundNum < 0 {
+				boundNum = 0
+			}
+			startKey = c.buildKeyName(boundNum)
+		} else {
+			endKey = c.buildKeyName(keyNum + scanLen)
+		}
+	}
+	_, err := rangeDB.RangeScan(ctx, c.table, startKey, endKey, reverse, int(scanLen), fields)
 	return err
 }
 
@@ -527,6 +603,20 @@ func (c *core) doTransactionUpdate(ctx context.Context, db ycsb.DB, state *coreS
 	return db.Update(ctx, c.table, keyName, values)
 }
 
+func (c *core) doTransactionDelete(ctx context.Context, db ycsb.DB, state *coreState) error {
+	keyNum, err := c.nextDeleteKeyNum(state)
+	if err != nil {
+		return err
+	}
+
+	if err := db.Delete(ctx, c.table, c.buildKeyName(keyNum)); err != nil {
+		// the key may still be there
+		c.deletedKeys.remove(keyNum)
+		return err
+	}
+	return nil
+}
+
 func (c *core) doBatchTransactionRead(ctx context.Context, batchSize int, db ycsb.BatchDB, state *coreState) error {
 	r := state.r
 	var fields []string
@@ -600,6 +690,31 @@ func (c *core) doBatchTransactionUpdate(ctx context.Context, batchSize int, db y
 	return db.BatchUpdate(ctx, c.table, keys, values)
 }
 
+func (c *core) doBatchTransactionDelete(ctx context.Context, batchSize int, db ycsb.BatchDB, state *coreState) error {
+	keyNums := make([]int64, 0, batchSize)
+	keys := make([]string, 0, batchSize)
+	for i := 0; i < batchSize; i++ {
+		keyNum, err := c.nextDeleteKeyNum(state)
+		if err != nil {
+			// delete the keys found
+			break
+		}
+		keyNums = append(keyNums, keyNum)
+		keys = append(keys, c.buildKeyName(keyNum))
+	}
+	if len(keys) == 0 {
+		return errNoKeyToDelete
+	}
+
+	if err := db.BatchDelete(ctx, c.table, keys); err != nil {
+		for _, keyNum := range keyNums {
+			c.deletedKeys.remove(keyNum)
+		}
+		return err
+	}
+	return nil
+}
+
 // CoreCreator creates the Core workload.
 type coreCreator struct {
 }
@@ -647,6 +762,10 @@ func (coreCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
 
 	c.keySequence = generator.NewCounter(insertStart)
 	c.operationChooser = createOperationGenerator(p)
+	if p.GetFloat64(prop.DeleteProportion, prop.DeleteProportionDefault) > 0 {
+		c.trackDeletes = true
+		c.deletedKeys = new(keySet)
+	}
 	var keyrangeLowerBound int64 = insertStart
 	var keyrangeUpperBound int64 = insertStart + insertCount - 1
 
@@ -687,6 +806,19 @@ func (coreCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
 		util.Fatalf("distribution %s not allowed for scan length", scanLengthDistrib)
 	}
 
+	c.scanReverseProportion = p.GetFloat64(prop.ScanReverseProportion, prop.ScanReverseProportionDefault)
+	c.scanEndBounded = p.GetBool(prop.ScanEndBounded, prop.ScanEndBoundedDefault)
+	if c.scanEndBounded {
+		// the bound is only scanLen records away in key order when the keys sort
+		// by their numbers, which are at most the loaded and inserted keys plus
+		// the longest scan.
+		maxKeyNum := insertStart + c.recordCount + p.GetInt64(prop.OperationCount, 0) + maxScanLength
+		padding := int64(len(strconv.FormatInt(maxKeyNum, 10)))
+		if !c.orderedInserts || c.zeroPadding < padding {
+			util.Fatalf("%s needs %s=ordered and %s of at least %d", prop.ScanEndBounded, prop.InsertOrder, prop.ZeroPadding, padding)
+		}
+	}
+
 	c.insertionRetryLimit = p.GetInt64(prop.InsertionRetryLimit, prop.InsertionRetryLimitDefault)
 	c.insertionRetryInterval = p.GetInt64(prop.InsertionRetryInterval, prop.InsertionRetryIntervalDefault)
 
@@ -0,0 +1,128 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// deleteDB counts the deletes of every key, and fails them if err is set.
+type deleteDB struct {
+	ycsb.BatchDB
+
+	deleted map[string]int
+	err     error
+}
+
+func (db *deleteDB) BatchDelete(ctx context.Context, table string, keys []string) error {
+	if db.err != nil {
+		return db.err
+	}
+	for _, key := range keys {
+		db.deleted[key]++
+	}
+	return nil
+}
+
+func newTestCore(t *testing.T, recordCount string) (*core, *coreState) {
+	p := properties.NewProperties()
+	p.Set(prop.RecordCount, recordCount)
+	p.Set(prop.InsertOrder, "ordered")
+	p.Set(prop.DeleteProportion, "1")
+	p.Set(prop.ReadProportion, "0")
+	p.Set(prop.UpdateProportion, "0")
+	w, err := coreCreator{}.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := w.(*core)
+	ctx := c.InitThread(context.Background(), 0, 1)
+	return c, ctx.Value(stateKey).(*coreState)
+}
+
+func TestKeySet(t *testing.T) {
+	s := new(keySet)
+	for _, keyNum := range []int64{0, 63, 64, 1000} {
+		if s.has(keyNum) || !s.add(keyNum) || !s.has(keyNum) || s.add(keyNum) {
+			t.Fatalf("%d isn't added once", keyNum)
+		}
+	}
+	s.remove(64)
+	s.remove(5000)
+	if s.has(64) || !s.has(63) || s.has(65) || s.has(5000) {
+		t.Fatal("unexpected keys after removed")
+	}
+}
+
+func TestNextKeyNumSkipsDeleted(t *testing.T) {
+	c, state := newTestCore(t, "10")
+	for keyNum := int64(0); keyNum < 10; keyNum++ {
+		if keyNum != 7 && keyNum != 8 {
+			c.deletedKeys.add(keyNum)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		if keyNum := c.nextKeyNum(state); keyNum != 7 && keyNum != 8 {
+			t.Fatalf("want a key not deleted, but got %d", keyNum)
+		}
+	}
+
+	first, err := c.nextDeleteKeyNum(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.nextDeleteKeyNum(state)
+	if err != nil || first+second != 15 {
+		t.Fatalf("want to delete 7 and 8, but got %d and %d, %v", first, second, err)
+	}
+	if _, err := c.nextDeleteKeyNum(state); err != errNoKeyToDelete {
+		t.Fatalf("want no key to delete, but got %v", err)
+	}
+}
+
+func TestBatchDelete(t *testing.T) {
+	c, state := newTestCore(t, "10")
+	db := &deleteDB{deleted: make(map[string]int)}
+
+	// a failed delete keeps the keys
+	db.err = errors.New("timeout")
+	if err := c.doBatchTransactionDelete(context.Background(), 4, db, state); err != db.err {
+		t.Fatalf("want the error of the db, but got %v", err)
+	}
+	db.err = nil
+
+	// every key is deleted once, the last batch deletes the keys left
+	for i := 0; i < 3; i++ {
+		if err := c.doBatchTransactionDelete(context.Background(), 4, db, state); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(db.deleted) != 10 {
+		t.Fatalf("want 10 keys deleted, but got %v", db.deleted)
+	}
+	for key, n := range db.deleted {
+		if n != 1 {
+			t.Fatalf("%s is deleted %d times", key, n)
+		}
+	}
+	if err := c.doBatchTransactionDelete(context.Background(), 4, db, state); err != errNoKeyToDelete {
+		t.Fatalf("want no key to delete, but got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import "sync"
+
+// keySet is a concurrent set of key numbers kept as a bitmap, so it takes a
+// bit for every key number up to the largest one added whatever the size is.
+type keySet struct {
+	mu    sync.RWMutex
+	words []uint64
+}
+
+func (s *keySet) has(keyNum int64) bool {
+	i, bit := keyNum/64, uint64(1)<<(keyNum%64)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return i < int64(len(s.words)) && s.words[i]&bit != 0
+}
+
+// add adds the key number, it returns false if it's already in the set.
+func (s *keySet) add(keyNum int64) bool {
+	i, bit := keyNum/64, uint64(1)<<(keyNum%64)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i >= int64(cap(s.words)) {
+		words := make([]uint64, i+1, 2*(i+1))
+		copy(words, s.words)
+		s.words = words
+	} else if i >= int64(len(s.words)) {
+		// the words after the length are never set
+		s.words = s.words[:i+1]
+	}
+	if s.words[i]&bit != 0 {
+		return false
+	}
+	s.words[i] |= bit
+	return true
+}
+
+func (s *keySet) remove(keyNum int64) {
+	i, bit := keyNum/64, uint64(1)<<(keyNum%64)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i < int64(len(s.words)) {
+		s.words[i] &^= bit
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/oplog"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+const replayStateKey = contextKey("replay")
+
+// replayQueueSize is the number of records queued for every thread.
+const replayQueueSize = 1024
+
+type replayState struct {
+	r        *rand.Rand
+	threadID int
+}
+
+// replay replays an operation log recorded with record.file. The records are
+// split across the threads by the hash of the key, so the operations on a key
+// are replayed in order by the same thread.
+type replay struct {
+	path string
+	// original replays the records at their offsets, otherwise as fast as
+	// possible.
+	original    bool
+	fieldNames  []string
+	fieldLength int
+	scanLength  int
+
+	startOnce sync.Once
+	start     time.Time
+	// base is the offset of the first record.
+	base  int64
+	queue []chan *oplog.Record
+	// quit is closed when the thread ends, its records are dropped then.
+	quit      []chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Close implements the Workload Close interface.
+func (r *replay) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.done)
+	})
+	return nil
+}
+
+// Finite implements the FiniteWorkload Finite interface.
+func (r *replay) Finite() {}
+
+// InitThread implements the Workload InitThread interface. The log is read
+// when the first thread starts, and it's replayed only once.
+func (r *replay) InitThread(ctx context.Context, threadID int, threadCount int) context.Context {
+	r.startOnce.Do(func() {
+		r.queue = make([]chan *oplog.Record, threadCount)
+		r.quit = make([]chan struct{}, threadCount)
+		for i := 0; i < threadCount; i++ {
+			r.queue[i] = make(chan *oplog.Record, replayQueueSize)
+			r.quit[i] = make(chan struct{})
+		}
+		r.start = time.Now()
+		go r.dispatch()
+	})
+
+	state := &replayState{
+		r:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		threadID: threadID,
+	}
+	return context.WithValue(ctx, replayStateKey, state)
+}
+
+// CleanupThread implements the Workload CleanupThread interface.
+func (r *replay) CleanupThread(ctx context.Context) {
+	state := ctx.Value(replayStateKey).(*replayState)
+	if state.threadID < len(r.quit) {
+		select {
+		case <-r.quit[state.threadID]:
+		default:
+			close(r.quit[state.threadID])
+		}
+	}
+}
+
+func (r *replay) dispatch() {
+	defer func() {
+		for _, q := range r.queue {
+			close(q)
+		}
+	}()
+
+	f, err := os.Open(r.path)
+	if err != nil {
+		util.Fatalf("open operation log %s failed %v", r.path, err)
+	}
+	defer f.Close()
+
+	reader := oplog.NewReader(f)
+	for first := true; ; first = false {
+		rec, err := reader.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			util.Fatalf("read operation log %s failed %v", r.path, err)
+		}
+		if first {
+			r.base = rec.Offset
+		}
+
+		i := uint64(util.StringHash64(rec.Key)) % uint64(len(r.queue))
+		select {
+		case r.queue[i] <- rec:
+		case <-r.quit[i]:
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// Load implements the Workload Load interface.
+func (r *replay) Load(ctx context.Context, db ycsb.DB, totalCount int64) error {
+	return nil
+}
+
+// DoInsert implements the Workload DoInsert interface, it replays the next
+// record like DoTransaction.
+func (r *replay) DoInsert(ctx context.Context, db ycsb.DB) error {
+	return r.DoTransaction(ctx, db)
+}
+
+// DoBatchInsert implements the Workload DoBatchInsert interface.
+func (r *replay) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) error {
+	return r.DoBatchTransaction(ctx, batchSize, db)
+}
+
+// DoTransaction implements the Workload DoTransaction interface.
+func (r *replay) DoTransaction(ctx context.Context, db ycsb.DB) error {
+	state := ctx.Value(replayStateKey).(*replayState)
+	if state.threadID >= len(r.queue) {
+		return ycsb.ErrWorkloadDone
+	}
+
+	var rec *oplog.Record
+	select {
+	case <-ctx.Done():
+		return ycsb.ErrWorkloadDone
+	case next, ok := <-r.queue[state.threadID]:
+		if !ok {
+			return ycsb.ErrWorkloadDone
+		}
+		rec = next
+	}
+
+	if r.original {
+		at := r.start.Add(time.Duration(rec.Offset-r.base) * time.Microsecond)
+		if d := time.Until(at); d > 0 {
+			select {
+			case <-ctx.Done():
+				return ycsb.ErrWorkloadDone
+			case <-time.After(d):
+			}
+		}
+	}
+
+	return r.replayRecord(ctx, state, db, rec)
+}
+
+// DoBatchTransaction implements the Workload DoBatchTransaction interface,
+// it replays the next batchSize records one by one.
+func (r *replay) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB) error {
+	for i := 0; i < batchSize; i++ {
+		err := r.DoTransaction(ctx, db)
+		if err == ycsb.ErrWorkloadDone && i > 0 {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *replay) replayRecord(ctx context.Context, state *replayState, db ycsb.DB, rec *oplog.Record) error {
+	var err error
+	switch rec.Op {
+	case oplog.OpRead:
+		_, err = db.Read(ctx, rec.Table, rec.Key, rec.Fields)
+	case oplog.OpUpdate:
+		err = db.Update(ctx, rec.Table, rec.Key, r.buildValues(state, rec))
+	case oplog.OpInsert:
+		err = db.Insert(ctx, rec.Table, rec.Key, r.buildValues(state, rec))
+	case oplog.OpDelete:
+		err = db.Delete(ctx, rec.Table, rec.Key)
+	case oplog.OpScan:
+		if rec.Bound == "" {
+			_, err = db.Scan(ctx, rec.Table, rec.Key, r.scanCount(rec), rec.Fields)
+		} else {
+			err = r.rangeScan(ctx, db, rec, rec.Key, rec.Bound, false)
+		}
+	case oplog.OpReverseScan:
+		err = r.rangeScan(ctx, db, rec, rec.Bound, rec.Key, true)
+	}
+	return err
+}
+
+func (r *replay) rangeScan(ctx context.Context, db ycsb.DB, rec *oplog.Record, startKey string, endKey string, reverse bool) error {
+	rangeDB, ok := db.(ycsb.RangeScanDB)
+	if !ok {
+		return fmt.Errorf("the %T doesn't implement the RangeScanDB interface", db)
+	}
+	_, err := rangeDB.RangeScan(ctx, rec.Table, startKey, endKey, reverse, r.scanCount(rec), rec.Fields)
+	return err
+}
+
+// buildValues returns random values of the fields and the size written by the
+// record, or of all fields and the fieldlength if the record doesn't have them.
+func (r *replay) buildValues(state *replayState, rec *oplog.Record) map[string][]byte {
+	fields := rec.Fields
+	if len(fields) == 0 {
+		fields = r.fieldNames
+	}
+	size := rec.ValueSize
+	if size == 0 {
+		size = r.fieldLength
+	}
+
+	values := make(map[string][]byte, len(fields))
+	for _, field := range fields {
+		buf := make([]byte, size)
+		util.RandBytes(state.r, buf)
+		values[field] = buf
+	}
+	return values
+}
+
+func (r *replay) scanCount(rec *oplog.Record) int {
+	if rec.Count > 0 {
+		return rec.Count
+	}
+	return r.scanLength
+}
+
+type replayCreator struct{}
+
+// Create implements the WorkloadCreator Create interface.
+func (replayCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
+	r := &replay{
+		path:        p.GetString(prop.ReplayFile, ""),
+		fieldLength: int(p.GetInt64(prop.FieldLength, prop.FieldLengthDefault)),
+		scanLength:  int(p.GetInt64(prop.MaxScanLength, prop.MaxScanLengthDefault)),
+		done:        make(chan struct{}),
+	}
+	if r.path == "" {
+		return nil, fmt.Errorf("%s must be set to replay", prop.ReplayFile)
+	}
+	if _, err := os.Stat(r.path); err != nil {
+		return nil, err
+	}
+
+	switch timing := p.GetString(prop.ReplayTiming, prop.ReplayTimingDefault); timing {
+	case "asap":
+	case "original":
+		r.original = true
+	default:
+		return nil, fmt.Errorf("unknown %s %s, must be asap or original", prop.ReplayTiming, timing)
+	}
+
+	fieldCount := p.GetInt64(prop.FieldCount, prop.FieldCountDefault)
+	r.fieldNames = make([]string, fieldCount)
+	for i := int64(0); i < fieldCount; i++ {
+		r.fieldNames[i] = fmt.Sprintf("field%d", i)
+	}
+	return r, nil
+}
+
+func init() {
+	ycsb.RegisterWorkloadCreator("replay", replayCreator{})
+}
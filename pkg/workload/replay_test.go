@@ -0,0 +1,195 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// callDB records the calls of every thread.
+type callDB struct {
+	ycsb.DB
+
+	mu    sync.Mutex
+	calls map[int][]string
+}
+
+func newCallDB() *callDB {
+	return &callDB{calls: make(map[int][]string)}
+}
+
+func (db *callDB) call(ctx context.Context, format string, args ...interface{}) {
+	threadID := ctx.Value(replayStateKey).(*replayState).threadID
+	db.mu.Lock()
+	db.calls[threadID] = append(db.calls[threadID], fmt.Sprintf(format, args...))
+	db.mu.Unlock()
+}
+
+func (db *callDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	db.call(ctx, "READ %s %s %v", table, key, fields)
+	return nil, nil
+}
+
+func (db *callDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	db.call(ctx, "UPDATE %s %s %d", table, key, len(values["field0"]))
+	return nil
+}
+
+func (db *callDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
+	db.call(ctx, "INSERT %s %s %d", table, key, len(values))
+	return nil
+}
+
+func (db *callDB) Delete(ctx context.Context, table string, key string) error {
+	db.call(ctx, "DELETE %s %s", table, key)
+	return nil
+}
+
+func (db *callDB) Scan(ctx context.Context, table string, startKey string, count int, fields []string) ([]map[string][]byte, error) {
+	db.call(ctx, "SCAN %s %s %d", table, startKey, count)
+	return nil, nil
+}
+
+func (db *callDB) RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, fields []string) ([]map[string][]byte, error) {
+	db.call(ctx, "RANGE_SCAN %s [%s, %s) %v %d", table, startKey, endKey, reverse, limit)
+	return nil, nil
+}
+
+func newTestReplay(t *testing.T, log string, timing string) ycsb.Workload {
+	path := filepath.Join(t.TempDir(), "ops.jsonl")
+	if err := os.WriteFile(path, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := properties.NewProperties()
+	p.Set(prop.ReplayFile, path)
+	p.Set(prop.ReplayTiming, timing)
+	p.Set(prop.FieldCount, "2")
+	p.Set(prop.MaxScanLength, "50")
+	w, err := replayCreator{}.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+// runReplay replays the log with the threads until it's done.
+func runReplay(w ycsb.Workload, db ycsb.DB, threadCount int) {
+	var wg sync.WaitGroup
+	for i := 0; i < threadCount; i++ {
+		ctx := w.InitThread(context.Background(), i, threadCount)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer w.CleanupThread(ctx)
+			for w.DoTransaction(ctx, db) == nil {
+			}
+		}()
+	}
+	wg.Wait()
+	w.Close()
+}
+
+func TestReplayDispatch(t *testing.T) {
+	log := `{"op":"READ","table":"t","key":"k1","fields":["field1"]}
+{"op":"UPDATE","table":"t","key":"k1","fields":["field0"],"value_size":10}
+{"op":"INSERT","table":"t","key":"k1"}
+{"op":"DELETE","table":"t","key":"k1"}
+{"op":"SCAN","table":"t","key":"k1","count":5}
+{"op":"SCAN","table":"t","key":"k1"}
+{"op":"SCAN","table":"t","key":"k1","bound":"k5","count":5}
+{"op":"REVERSE_SCAN","table":"t","key":"k1","bound":"k0","count":5}
+{"op":"REVERSE_SCAN","table":"t","key":"","count":5}
+`
+	db := newCallDB()
+	runReplay(newTestReplay(t, log, "asap"), db, 1)
+
+	want := []string{
+		"READ t k1 [field1]",
+		"UPDATE t k1 10",
+		"INSERT t k1 2",
+		"DELETE t k1",
+		"SCAN t k1 5",
+		"SCAN t k1 50",
+		"RANGE_SCAN t [k1, k5) false 5",
+		"RANGE_SCAN t [k0, k1) true 5",
+		"RANGE_SCAN t [, ) true 5",
+	}
+	if !reflect.DeepEqual(db.calls[0], want) {
+		t.Fatalf("want calls\n%s\nbut got\n%s", strings.Join(want, "\n"), strings.Join(db.calls[0], "\n"))
+	}
+}
+
+func TestReplayRouting(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&b, "{\"op\":\"DELETE\",\"table\":\"t\",\"key\":\"k%d\"}\n", i%10)
+		fmt.Fprintf(&b, "{\"op\":\"READ\",\"table\":\"t\",\"key\":\"k%d\"}\n", i%10)
+	}
+	db := newCallDB()
+	runReplay(newTestReplay(t, b.String(), "asap"), db, 4)
+
+	// the operations on a key are replayed by a thread in order
+	threads := make(map[string]int)
+	total := 0
+	for threadID, calls := range db.calls {
+		last := make(map[string]string)
+		for _, c := range calls {
+			fields := strings.Fields(c)
+			op, key := fields[0], fields[2]
+			if id, ok := threads[key]; ok && id != threadID {
+				t.Fatalf("%s is replayed by thread %d and %d", key, id, threadID)
+			}
+			threads[key] = threadID
+			if op == last[key] {
+				t.Fatalf("the operations on %s are out of order", key)
+			}
+			last[key] = op
+		}
+		total += len(calls)
+	}
+	if total != 200 || len(threads) != 10 {
+		t.Fatalf("want 200 operations on 10 keys, but got %d on %d keys", total, len(threads))
+	}
+}
+
+func TestReplayTiming(t *testing.T) {
+	log := `{"offset_us":1000000,"op":"READ","table":"t","key":"k1"}
+{"offset_us":1100000,"op":"READ","table":"t","key":"k1"}
+`
+	for _, timing := range []string{"asap", "original"} {
+		start := time.Now()
+		runReplay(newTestReplay(t, log, timing), newCallDB(), 1)
+		elapsed := time.Since(start)
+
+		// the offsets are relative to the first record
+		if timing == "original" && (elapsed < 100*time.Millisecond || elapsed >= time.Second) {
+			t.Errorf("original: want the log replayed in 100ms, but it takes %s", elapsed)
+		}
+		if timing == "asap" && elapsed >= 100*time.Millisecond {
+			t.Errorf("asap: want the log replayed at once, but it takes %s", elapsed)
+		}
+	}
+}
@@ -95,6 +95,49 @@ type BatchDB interface {
 	BatchDelete(ctx context.Context, table string, keys []string) error
 }
 
+// RangeScanDB is the interface for the DB that can scan a bounded range of
+// keys in either direction.
+type RangeScanDB interface {
+	// RangeScan scans records in a key range from the database.
+	// table: The name of the table.
+	// startKey: The lower bound of the range, inclusive, empty for the first record of the table.
+	// endKey: The upper bound of the range, exclusive, empty for the last record of the table.
+	// reverse: Whether to scan from the upper bound in descending key order.
+	// limit: The maximum number of records to read.
+	// fields: The list of fields to read, nil|empty for reading all.
+	RangeScan(ctx context.Context, table string, startKey string, endKey string, reverse bool, limit int, fields []string) ([]map[string][]byte, error)
+}
+
+// TxnDB is the interface for the DB that supports multi-key transactions.
+type TxnDB interface {
+	// Begin starts a transaction, which is ended by Commit or Abort.
+	Begin(ctx context.Context) (Txn, error)
+}
+
+// Txn is a transaction of a TxnDB. A record read and then written in a
+// transaction can't be changed by other transactions in between, or the
+// commit fails, so a read-modify-write in a transaction is atomic.
+type Txn interface {
+	// Read reads a record in the transaction.
+	// table: The name of the table.
+	// key: The record key of the record to read.
+	// fields: The list of fields to read, nil|empty for reading all.
+	Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error)
+
+	// Write updates a record in the transaction, the fields not in the values
+	// are kept.
+	// table: The name of the table.
+	// key: The record key of the record to write.
+	// values: A map of field/value pairs to update in the record.
+	Write(ctx context.Context, table string, key string, values map[string][]byte) error
+
+	// Commit commits the transaction, the transaction is ended even if it fails.
+	Commit(ctx context.Context) error
+
+	// Abort rolls back the transaction.
+	Abort(ctx context.Context) error
+}
+
 // AnalyzeDB is the interface for the DB that can perform an analysis on given table.
 type AnalyzeDB interface {
 	// Analyze performs a key distribution analysis for the table.
@@ -15,6 +15,7 @@ package ycsb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/magiconair/properties"
@@ -53,6 +54,29 @@ type Workload interface {
 	DoBatchTransaction(ctx context.Context, batchSize int, db DB) error
 }
 
+// ErrWorkloadDone is returned by the operations of a FiniteWorkload when it
+// has no operation left, the worker stops then.
+var ErrWorkloadDone = errors.New("workload is done")
+
+// FiniteWorkload is the interface for the Workload which ends by itself, like
+// a replayed trace, so the operation count can be left unlimited.
+type FiniteWorkload interface {
+	Workload
+
+	// Finite marks the workload as finite.
+	Finite()
+}
+
+// ValidatedWorkload is the interface for the Workload which checks the data
+// after a run, like whether the transactions keep an invariant.
+type ValidatedWorkload interface {
+	Workload
+
+	// Validate checks the data in the DB, it returns an error if the check
+	// fails.
+	Validate(ctx context.Context, db DB) error
+}
+
 var workloadCreators = map[string]WorkloadCreator{}
 
 // RegisterWorkloadCreator registers a creator for the workload
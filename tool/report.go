@@ -11,235 +11,144 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// report generates an HTML page with charts and a Markdown summary from the
+// outputs, reports or raw latency files of go-ycsb runs.
+//
+// Usage:
+//
+//	go run tool/report.go [-html report.html] [-markdown report.md] [[db[/workload]=]path ...]
+//
+// The database and the workload of a file are taken from its name like
+// tikv_workloada.log written by tool/binary/bench.sh, unless given before
+// "=". The files of the same database and workload, like rotated raw
+// latency files, are loaded as one run. A directory stands for the files in
+// it, and ./logs is used if no path is given.
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
-	"path"
-	"sort"
-	"strconv"
+	"path/filepath"
 	"strings"
+
+	"github.com/pingcap/go-ycsb/pkg/report"
 )
 
 var (
-	logsPath   = "./logs"
-	operations = map[string]struct{}{
-		"INSERT":            struct{}{},
-		"READ":              struct{}{},
-		"UPDATE":            struct{}{},
-		"SCAN":              struct{}{},
-		"READ_MODIFY_WRITE": struct{}{},
-		"DELETE":            struct{}{},
-	}
-	workloads = map[string]struct{}{
-		"load":      struct{}{},
-		"workloada": struct{}{},
-		"workloadb": struct{}{},
-		"workloadc": struct{}{},
-		"workloadd": struct{}{},
-		"workloade": struct{}{},
-		"workloadf": struct{}{},
-	}
+	htmlPath     = flag.String("html", "report.html", "Output HTML file, empty to skip")
+	markdownPath = flag.String("markdown", "report.md", "Output Markdown file, empty to skip")
 )
 
-type stat struct {
-	OPS float64
-	P99 float64
-}
-
-func statFieldFunc(c rune) bool {
-	return c == ':' || c == ','
-}
-
-func newStat(line string) (*stat, error) {
-	kvs := strings.FieldsFunc(line, statFieldFunc)
-	s := stat{}
-	if len(kvs)%2 != 0 {
-		println(line)
-	}
-	for i := 0; i < len(kvs); i += 2 {
-		v, err := strconv.ParseFloat(strings.TrimSpace(kvs[i+1]), 64)
-		if err != nil {
-			return nil, err
-		}
-		switch strings.TrimSpace(kvs[i]) {
-		case "OPS":
-			s.OPS = v
-		case "99th(us)":
-			s.P99 = v
-		default:
-		}
-	}
-	return &s, nil
-}
-
-type dbStat struct {
+type runFiles struct {
 	db       string
 	workload string
-	summary  map[string]*stat
-	// progress map[string][]*stat
+	paths    []string
 }
 
-func parseDBStat(pathName string) (*dbStat, error) {
-	// check db and workload from file name, the name format is:
-	// 	1. db_load.log
-	// 	2. db_run_workloadx.log
-	s := new(dbStat)
-	s.summary = make(map[string]*stat, 1)
-	// s.progress = make(map[string][]*stat, 1)
-
-	fileName := path.Base(pathName)
-	seps := strings.Split(fileName, "_")
-
-	if len(seps) != 2 {
-		return nil, nil
-	}
-
-	s.db = seps[0]
-	workload := strings.TrimSuffix(seps[1], ".log")
-	if _, ok := workloads[workload]; !ok {
-		return nil, nil
-	}
-
-	s.workload = workload
-	file, err := os.Open(pathName)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	handleSummary := false
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "Run finished") {
-			handleSummary = true
-			continue
+// collect groups the files by the database and the workload.
+func collect(args []string) ([]*runFiles, error) {
+	var runs []*runFiles
+	byName := make(map[string]*runFiles)
+	add := func(db string, workload string, path string) {
+		key := db + "/" + workload
+		r, ok := byName[key]
+		if !ok {
+			r = &runFiles{db: db, workload: workload}
+			byName[key] = r
+			runs = append(runs, r)
 		}
+		r.paths = append(r.paths, path)
+	}
 
-		seps := strings.Split(line, "-")
-		op := strings.TrimSpace(seps[0])
-		if _, ok := operations[op]; !ok {
-			continue
+	for _, arg := range args {
+		name, path := "", arg
+		if seps := strings.SplitN(arg, "=", 2); len(seps) == 2 {
+			name, path = seps[0], seps[1]
 		}
 
-		stat, err := newStat(strings.TrimSpace(seps[1]))
+		info, err := os.Stat(path)
 		if err != nil {
 			return nil, err
 		}
-
-		if handleSummary {
-			// handle summary logs
-			s.summary[op] = stat
+		files := []string{path}
+		if info.IsDir() {
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return nil, err
+			}
+			files = files[:0]
+			for _, e := range entries {
+				if !e.IsDir() {
+					files = append(files, filepath.Join(path, e.Name()))
+				}
+			}
 		}
 
-		// TODO  handle progress logs
-		// s.progress[op] = append(s.progress[op], stat)
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
+		for _, file := range files {
+			db, workload := report.RunName(file)
+			if name != "" {
+				db, workload = name, ""
+				if seps := strings.SplitN(name, "/", 2); len(seps) == 2 {
+					db, workload = seps[0], seps[1]
+				}
+			}
+			add(db, workload, file)
+		}
 	}
-	return s, nil
+	return runs, nil
 }
 
-type dbStats []*dbStat
-
-func (a dbStats) Len() int           { return len(a) }
-func (a dbStats) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a dbStats) Less(i, j int) bool { return a[i].db < a[j].db }
-
-func reportDBStats(logsPath string, workload string, stats dbStats) error {
-	dir := path.Join(logsPath, "report")
-	os.MkdirAll(dir, 0755)
-
-	fileName := path.Join(dir, fmt.Sprintf("%s_summary.csv", workload))
-	sort.Sort(stats)
-
-	file, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE, 0644)
+func writeFile(path string, write func(w io.Writer, runs []*report.Run) error, runs []*report.Run) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Create(path)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	var fields []string
-	switch workload {
-	case "load":
-		fields = []string{"INSERT"}
-	case "workloada":
-		fields = []string{"READ", "UPDATE"}
-	case "workloadb":
-		fields = []string{"READ", "UPDATE"}
-	case "workloadc":
-		fields = []string{"READ"}
-	case "workloadd":
-		fields = []string{"READ", "INSERT"}
-	case "workloade":
-		fields = []string{"SCAN", "INSERT"}
-	case "workloadf":
-		fields = []string{"READ_MODIFY_WRITE"}
-	default:
-	}
-
-	fmt.Fprintf(file, "DB")
-	for _, field := range fields {
-		fmt.Fprintf(file, ",%s OPS,%s P99(us)", field, field)
-	}
-	fmt.Fprint(file, "\n")
-
-	for _, stat := range stats {
-		fmt.Fprintf(file, "%s", stat.db)
-		for _, field := range fields {
-			s := stat.summary[field]
-			if s == nil {
-				fmt.Fprintf(file, ",0.0")
-			} else {
-				fmt.Fprintf(file, ",%.f,%.f", s.OPS, s.P99)
-			}
-		}
-		fmt.Fprintf(file, "\n")
+	if err := write(f, runs); err != nil {
+		f.Close()
+		return err
 	}
-
-	return nil
+	return f.Close()
 }
 
 func main() {
-	if len(os.Args) >= 2 {
-		logsPath = os.Args[1]
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"./logs"}
 	}
 
-	files, err := ioutil.ReadDir(logsPath)
+	files, err := collect(args)
 	if err != nil {
-		println(err.Error())
-		return
+		fmt.Println(err)
+		os.Exit(1)
 	}
 
-	stats := make(map[string][]*dbStat)
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
-
-		s, err := parseDBStat(path.Join(logsPath, file.Name()))
-
+	var runs []*report.Run
+	for _, f := range files {
+		run, err := report.LoadRun(f.db, f.workload, f.paths)
 		if err != nil {
-			fmt.Printf("parse %s failed %v\n", file.Name(), err.Error())
-		}
-
-		if s == nil {
+			// the directories may have other files
+			fmt.Printf("skip %s: %v\n", strings.Join(f.paths, ", "), err)
 			continue
 		}
-
-		stats[s.workload] = append(stats[s.workload], s)
+		runs = append(runs, run)
+	}
+	if len(runs) == 0 {
+		fmt.Println("no run found")
+		os.Exit(1)
 	}
 
-	for workload, s := range stats {
-		if err := reportDBStats(logsPath, workload, s); err != nil {
-			fmt.Printf("report %s failed %v\n", workload, err)
-		}
+	if err := writeFile(*htmlPath, report.WriteHTML, runs); err != nil {
+		fmt.Printf("write %s failed %v\n", *htmlPath, err)
+		os.Exit(1)
+	}
+	if err := writeFile(*markdownPath, report.WriteMarkdown, runs); err != nil {
+		fmt.Printf("write %s failed %v\n", *markdownPath, err)
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,84 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pingcap/go-ycsb/pkg/report"
+)
+
+func TestReport(t *testing.T) {
+	dir := t.TempDir()
+	out := "READ   - Takes(s): 1.0, Count: 10, OPS: 10.0, Avg(us): 1, Min(us): 0, Max(us): 1, 99th(us): 1, 99.9th(us): 1, 99.99th(us): 1\n"
+	for _, name := range []string{"tikv_workloada.log", "mysql_workloada.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(out), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := filepath.Join(t.TempDir(), "out.log")
+	if err := os.WriteFile(other, []byte(out), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := collect([]string{dir, "pg/workloadb=" + other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	var runs []*report.Run
+	for _, f := range files {
+		names = append(names, f.db+"/"+f.workload)
+		run, err := report.LoadRun(f.db, f.workload, f.paths)
+		if err != nil {
+			t.Fatal(err)
+		}
+		runs = append(runs, run)
+	}
+	if got := strings.Join(names, ","); got != "mysql/workloada,tikv/workloada,pg/workloadb" {
+		t.Fatalf("unexpected runs %s", got)
+	}
+
+	htmlFile := filepath.Join(dir, "report.html")
+	mdFile := filepath.Join(dir, "report.md")
+	if err := writeFile(htmlFile, report.WriteHTML, runs); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(mdFile, report.WriteMarkdown, runs); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile("", report.WriteMarkdown, runs); err != nil {
+		t.Fatal(err)
+	}
+
+	md, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"## workloada\n", "## workloadb\n", "|READ|tikv|10|10.0|", "|READ|pg|10|10.0|"} {
+		if !strings.Contains(string(md), s) {
+			t.Errorf("no %q in the Markdown", s)
+		}
+	}
+	page, err := os.ReadFile(htmlFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), "<h2>workloadb</h2>") || !strings.Contains(string(page), "<svg") {
+		t.Errorf("unexpected HTML %s", page)
+	}
+}